@@ -14,7 +14,6 @@ func main() {
 		Name:      os.Getenv("NAMESPACE"),
 		Namespace: os.Getenv("NAMESPACE"),
 		Secret:    os.Getenv("JWT_SECRET"),
-		Records:   make(map[string]string),
 		Version:   Version,
 	})
 
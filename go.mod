@@ -9,6 +9,7 @@ require (
 	github.com/charmbracelet/log v0.4.1
 	github.com/golang-jwt/jwt/v4 v4.5.2
 	golang.design/x/clipboard v0.7.0
+	golang.org/x/crypto v0.32.0
 	google.golang.org/grpc v1.71.1
 	google.golang.org/protobuf v1.36.4
 	gopkg.in/yaml.v2 v2.4.0
@@ -1,10 +1,14 @@
 package app
 
 import (
+	"net"
 	"net/http"
 	"os"
 	"prx/internal/models"
 	"prx/internal/services"
+	"prx/internal/utils"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,15 +16,39 @@ import (
 )
 
 type App struct {
-	Jwt             *services.JWTService
-	Log             *log.Logger
-	Api             *http.Server
-	Kube            services.Kube
-	mu              sync.Mutex
-	namespace       string
-	name            string
-	version         string
-	RedirectRecords map[string]string
+	Jwt              *services.JWTService
+	Log              *log.Logger
+	Api              *http.Server
+	TLS              *http.Server
+	Certs            *services.CertStore
+	Kube             services.Kube
+	Health           *services.HealthChecker
+	mu               sync.Mutex
+	writes           sync.Mutex
+	namespace        string
+	name             string
+	version          string
+	RedirectRecords  map[string]services.ProxyMapping
+	hosts            *hostIndex
+	Transport        *http.Transport
+	upstreamSettings services.TransportSettings
+	server           services.ServerSettings
+	Retries          *services.RetryBudget
+	Cache            *services.Cache
+	Mirror           *services.Mirror
+	EdgeAuth         *services.EdgeAuth
+	ErrorPages       *services.ErrorPages
+	UpstreamTLS      *services.UpstreamTLS
+	ACME             *services.ACME
+	globalLimit      *rateLimit
+	globalDeny       utils.CIDRList
+	trustedProxies   utils.CIDRList
+	handlers         map[string]*recordHandler
+	labeledHandlers  *handlerLRU
+	tlsAddr          string
+	passthrough      bool
+	proxyProtocol    bool
+	tcpListeners     map[string]net.Listener
 }
 
 func NewProxy(settings models.NewProxySettings) *App {
@@ -34,13 +62,49 @@ func NewProxy(settings models.NewProxySettings) *App {
 		Prefix:          "go_proxy",
 	})
 
+	upstreamSettings := services.TransportSettingsFromEnv()
+	serverSettings := services.ServerSettingsFromEnv()
+	transport := services.NewTransport(upstreamSettings)
+
 	app := &App{
-		Jwt:             services.NewJwtService(settings.Secret),
-		Log:             logger,
-		RedirectRecords: settings.Records,
-		namespace:       settings.Namespace,
-		name:            settings.Name,
-		version:         settings.Version,
+		Jwt:              services.NewJwtService(settings.Secret),
+		Log:              logger,
+		Health:           services.NewHealthChecker(logger),
+		RedirectRecords:  make(map[string]services.ProxyMapping),
+		hosts:            &hostIndex{},
+		Transport:        transport,
+		upstreamSettings: upstreamSettings,
+		server:           serverSettings,
+		Retries:          services.NewRetryBudgetFromEnv(),
+		Cache:            services.NewCacheFromEnv(logger),
+		Mirror:           services.NewMirrorFromEnv(logger, transport),
+		handlers:         make(map[string]*recordHandler),
+		labeledHandlers:  newHandlerLRU(defaultLabeledHandlers),
+		tcpListeners:     make(map[string]net.Listener),
+		namespace:        settings.Namespace,
+		name:             settings.Name,
+		version:          settings.Version,
+	}
+
+	if cfg := services.RateLimitFromEnv(); cfg != nil {
+		if errMsg := utils.ValidateRateLimit(*cfg); errMsg != "" {
+			logger.Fatal("Invalid global rate limit:", "error", errMsg)
+		}
+		app.globalLimit = newRateLimit(cfg)
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("PRX_LABELED_HANDLERS_MAX")); err == nil && n > 0 {
+		app.labeledHandlers = newHandlerLRU(n)
+	}
+
+	if app.trustedProxies, err = utils.ParseCIDRs(strings.Split(os.Getenv("PRX_TRUSTED_PROXIES"), ",")); err != nil {
+		logger.Fatal("Invalid PRX_TRUSTED_PROXIES:", "error", err)
+	}
+	if app.proxyProtocol, _ = strconv.ParseBool(os.Getenv("PRX_PROXY_PROTOCOL")); app.proxyProtocol && len(app.trustedProxies) == 0 {
+		logger.Warn("PRX_PROXY_PROTOCOL is set but PRX_TRUSTED_PROXIES is empty, no PROXY protocol header will be accepted")
+	}
+	if app.globalDeny, err = utils.ParseCIDRs(strings.Split(os.Getenv("PRX_DENY_CIDRS"), ",")); err != nil {
+		logger.Fatal("Invalid PRX_DENY_CIDRS:", "error", err)
 	}
 
 	app.Kube, err = services.NewKubeClient(logger)
@@ -48,18 +112,70 @@ func NewProxy(settings models.NewProxySettings) *App {
 		panic(err)
 	}
 
+	app.EdgeAuth = services.NewEdgeAuthFromEnv(func(name string) (map[string][]byte, error) {
+		return app.Kube.GetSecretData(app.namespace, name)
+	}, transport)
+
+	app.UpstreamTLS = services.NewUpstreamTLSFromEnv(logger, func(name string) (map[string][]byte, error) {
+		return app.Kube.GetSecretData(app.namespace, name)
+	})
+
+	app.ErrorPages = services.NewErrorPagesFromEnv(logger, func(name string) (map[string]string, error) {
+		return app.Kube.GetConfigMapData(app.namespace, name)
+	})
+
+	if enabled, _ := strconv.ParseBool(os.Getenv("PRX_ACME")); enabled {
+		app.ACME = services.NewACMEFromEnv(logger, app.Kube.Secrets(app.namespace), app.refreshCerts)
+	}
+
+	// gRPC clients talk cleartext HTTP/2 with prior knowledge to port 80.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
 	app.Api = &http.Server{
-		Addr:    ":80",
-		Handler: app.CreateRoutes(),
+		Addr:              ":80",
+		Handler:           app.CreateRoutes(),
+		Protocols:         protocols,
+		ReadHeaderTimeout: serverSettings.ReadHeaderTimeout,
+		ReadTimeout:       serverSettings.ReadTimeout,
+		WriteTimeout:      serverSettings.WriteTimeout,
+		IdleTimeout:       serverSettings.IdleTimeout,
+		MaxHeaderBytes:    serverSettings.MaxHeaderBytes,
+	}
+
+	if app.tlsAddr = os.Getenv("PRX_TLS_ADDR"); app.tlsAddr == "" {
+		app.tlsAddr = ":443"
 	}
+	if enabled, _ := strconv.ParseBool(os.Getenv("PRX_TLS")); enabled {
+		app.Certs = services.NewCertStoreFromEnv(logger, func() ([]services.TLSSecret, error) {
+			return app.Kube.ListTLSSecrets(app.namespace)
+		})
+		app.TLS = app.newTLSServer(app.tlsAddr, app.Api)
+	}
+	app.passthrough, _ = strconv.ParseBool(os.Getenv("PRX_TLS_PASSTHROUGH"))
 
 	return app
 }
 
 func (a *App) Start() {
+	a.loadRedirectRecords()
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	if a.ACME != nil {
+		go a.startACME()
+	}
+
+	if a.TLS != nil || a.passthrough {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.startTLS()
+		}()
+	}
+
 	go func() {
 		defer wg.Done()
 		a.startApi()
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"prx/internal/utils"
+	"strings"
+)
+
+type requestInfoKey struct{}
+
+// requestInfo is attached to every request by LoggingMiddleware. It carries
+// the real client IP and the IP access decision, which is logged once the
+// request is done.
+type requestInfo struct {
+	clientIP string
+	access   string
+	rule     string
+}
+
+func requestInfoFrom(req *http.Request) *requestInfo {
+	info, _ := req.Context().Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+// realClientIP returns the address of the client. X-Forwarded-For is only
+// believed while the hop that added an entry is a trusted proxy: entries are
+// read from the right and the first untrusted address is the client.
+func (a *App) realClientIP(req *http.Request) string {
+	ip := remoteIP(req)
+	if !a.trustedProxies.Contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, v := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !a.trustedProxies.Contains(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// keepForwardedFor extends the X-Forwarded-For chain of a trusted proxy
+// instead of starting a new one, so upstreams see the real client as well.
+// It runs after httputil.ProxyRequest.SetXForwarded.
+func (a *App) keepForwardedFor(in, out *http.Request) {
+	prior := in.Header.Values("X-Forwarded-For")
+	if len(prior) == 0 || !a.trustedProxies.Contains(remoteIP(in)) {
+		return
+	}
+	out.Header.Set("X-Forwarded-For", strings.Join(prior, ", ")+", "+remoteIP(in))
+}
+
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// allowIP checks the client IP against deny and allow lists and reports
+// whether the request may continue. A matching deny range wins; a non-empty
+// allow list admits only the clients it contains. Rejected requests get a
+// 403 and the decision is recorded for LoggingMiddleware.
+func allowIP(w http.ResponseWriter, req *http.Request, scope string, allow, deny utils.CIDRList) bool {
+	decision, rule := accessDecision(clientIP(req), scope, allow, deny)
+
+	if info := requestInfoFrom(req); info != nil && (rule != "" || info.access == "") {
+		info.access, info.rule = decision, rule
+	}
+	if decision == "deny" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// accessDecision returns "allow" or "deny" for ip and the rule that decided.
+func accessDecision(ip, scope string, allow, deny utils.CIDRList) (string, string) {
+	if p, ok := deny.Match(ip); ok {
+		return "deny", scope + " deny " + p.String()
+	}
+	if len(allow) > 0 {
+		if p, ok := allow.Match(ip); ok {
+			return "allow", scope + " allow " + p.String()
+		}
+		return "deny", scope + " allow list"
+	}
+	return "allow", ""
+}
+
+// AccessMiddleware rejects proxied requests from clients in the global deny
+// list before a record is looked up.
+func (a *App) AccessMiddleware(next http.Handler) http.Handler {
+	if len(a.globalDeny) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowIP(w, r, "global", nil, a.globalDeny) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func withRequestInfo(req *http.Request, info *requestInfo) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), requestInfoKey{}, info))
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"prx/internal/models"
+)
+
+// ACMEChallengeMiddleware answers HTTP-01 challenges before the global deny
+// list and rate limit, which must not keep the CA from validating a host.
+func (a *App) ACMEChallengeMiddleware(next http.Handler) http.Handler {
+	if a.ACME == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.ACME.ServeChallenge(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// certificateError checks the uploaded certificate of a record. Both the
+// certificate and the key are needed unless ACME issues them.
+func (a *App) certificateError(cert, key string) string {
+	switch {
+	case (cert == "") != (key == ""):
+		return "cert and key must be given together"
+	case cert == "" && a.ACME == nil:
+		return "cert and key are required unless ACME is enabled"
+	}
+	return ""
+}
+
+// certificateStatus reports the ACME certificate of a record, if it has one.
+func (a *App) certificateStatus(host string) *models.CertificateStatus {
+	if a.ACME == nil {
+		return nil
+	}
+	return a.ACME.Status(host)
+}
+
+func (a *App) startACME() {
+	a.Log.Info("ACME client started")
+	a.ACME.Run(context.Background())
+}
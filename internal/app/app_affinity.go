@@ -0,0 +1,78 @@
+package app
+
+import (
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"time"
+)
+
+const defaultAffinityCookie = "prx_affinity"
+
+// pickBackend chooses the upstream of a request, honouring the record's
+// session affinity before its balancing policy. A client pinned to an
+// upstream that is no longer available falls through to the policy and is
+// pinned again by the response.
+func (h *recordHandler) pickBackend(req *http.Request, available func(*services.Backend) bool) (*services.Backend, error) {
+	if a := h.record.Affinity; a != nil {
+		switch a.Type {
+		case models.AffinityCookie:
+			if be := h.balancer.Lookup(h.affinityCookie(req)); be != nil && available(be) {
+				return be, nil
+			}
+		case models.AffinityHeader:
+			if key := req.Header.Get(a.Header); key != "" {
+				return h.balancer.PickHash(key, available)
+			}
+		case models.AffinityIP:
+			return h.balancer.PickHash(clientIP(req), available)
+		}
+	}
+	return h.balancer.Pick(upstreamHashKey(h.record, req), available)
+}
+
+// affinityCookie returns the upstream ID a client is pinned to by cookie.
+func (h *recordHandler) affinityCookie(req *http.Request) string {
+	a := h.record.Affinity
+	if a == nil || a.Type != models.AffinityCookie {
+		return ""
+	}
+	cookie, err := req.Cookie(affinityCookieName(a))
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// stickAffinity pins the client to the upstream that answered, unless its
+// cookie already names that upstream. The cookie only holds the upstream ID,
+// so every replica resolves it the same way.
+func (h *recordHandler) stickAffinity(res *http.Response) {
+	a := h.record.Affinity
+	if a == nil || a.Type != models.AffinityCookie {
+		return
+	}
+	target, ok := res.Request.Context().Value(upstreamTargetKey{}).(*upstreamTarget)
+	if !ok || target.backend == nil || target.backend.ID == target.affinity {
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     affinityCookieName(a),
+		Value:    target.backend.ID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if ttl, err := time.ParseDuration(a.CookieTTL); err == nil {
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	res.Header.Add("Set-Cookie", cookie.String())
+}
+
+func affinityCookieName(a *models.SessionAffinity) string {
+	if a.Cookie != "" {
+		return a.Cookie
+	}
+	return defaultAffinityCookie
+}
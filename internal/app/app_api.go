@@ -14,8 +14,13 @@ func (a *App) startApi() {
 
 	a.printSettings(jwt, os.Getenv("JWT_SECRET"))
 
-	a.Log.Info("Server started on port 80")
-	if err := a.Api.ListenAndServe(); err != nil {
+	ln, err := a.listen(a.Api.Addr)
+	if err != nil {
+		a.Log.Fatal("Server failed to start:", "error", err)
+	}
+
+	a.Log.Info("Server started on port 80", "proxyProtocol", a.proxyProtocol)
+	if err := a.Api.Serve(ln); err != nil {
 		a.Log.Fatal("Server failed to start:", "error", err)
 	}
 }
@@ -29,12 +34,8 @@ func (a *App) CreateRoutes() http.Handler {
 
 func (a *App) proxyRoutes() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /", a.HandleRequests)
-	mux.HandleFunc("PUT /", a.HandleRequests)
-	mux.HandleFunc("POST /", a.HandleRequests)
-	mux.HandleFunc("PATCH /", a.HandleRequests)
-	mux.HandleFunc("DELETE /", a.HandleRequests)
-	return a.LoggingMiddleware(mux)
+	mux.HandleFunc("/", a.HandleRequests)
+	return a.LoggingMiddleware(a.ACMEChallengeMiddleware(a.GRPCErrorMiddleware(a.AccessMiddleware(a.RateLimitMiddleware(mux)))))
 }
 
 func (a *App) apiRoutes() http.Handler {
@@ -44,5 +45,8 @@ func (a *App) apiRoutes() http.Handler {
 	mux.HandleFunc("POST /api/prx", a.HandleAddNewProxy)
 	mux.HandleFunc("PATCH /api/prx", a.HandlePatchProxy)
 	mux.HandleFunc("DELETE /api/prx", a.HandleDeleteProxy)
+	mux.HandleFunc("DELETE /api/prx/cache", a.HandlePurgeCache)
+	mux.HandleFunc("POST /api/prx/canary", a.HandleCanary)
+	mux.HandleFunc("POST /api/prx/maintenance", a.HandleMaintenance)
 	return a.AuthenticationMiddleware(mux)
 }
@@ -2,31 +2,40 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"prx/internal/models"
+	"prx/internal/services"
 	"prx/internal/utils"
 	"time"
 )
 
 func (a *App) HandleRequests(w http.ResponseWriter, req *http.Request) {
 
-	targetURL, err := a.getRedirectionRecords(req.Host)
+	record, labels, err := a.getRedirectionRecords(req.Host)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		if !a.serveErrorPage(w, req, "", "", http.StatusNotFound, err.Error()) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+		return
 	}
 
-	a.Log.Debug("Proxying request", "host", req.Host, "target", targetURL)
+	// tls and tcp records are not served over HTTP.
+	if isL4(record.Protocol) {
+		msg := fmt.Sprintf("no http record found for host %s", req.Host)
+		if !a.serveErrorPage(w, req, "", "", http.StatusNotFound, msg) {
+			http.Error(w, msg, http.StatusNotFound)
+		}
+		return
+	}
 
-	parsedURL, err := url.Parse(targetURL)
+	handler, err := a.recordHandler(record, labels)
 	if err != nil {
-		a.Response(w, a.Err("invalid url %s", err), http.StatusInternalServerError)
+		a.respondError(w, req, record.ErrorPages, a.Err("invalid record for host %s: %s", req.Host, err), http.StatusBadGateway)
 		return
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(parsedURL)
-	proxy.ServeHTTP(w, req)
+	handler.ServeHTTP(w, req)
 }
 
 func (a *App) HandleAddNewProxy(w http.ResponseWriter, req *http.Request) {
@@ -42,13 +51,30 @@ func (a *App) HandleAddNewProxy(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if errMsg := a.recordError(body.From, body.Cert, body.Key, body.ProxySpec); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := utils.ValidateProxySpec(body.From, body.To, body.ProxySpec); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	a.writes.Lock()
+	defer a.writes.Unlock()
+
 	err := a.Kube.AddNewProxy(body, a.namespace, a.name)
 	if err != nil {
 		a.Response(w, a.Err("configuration error: %s", err), http.StatusInternalServerError)
 		return
 	}
 
-	a.setRedirectRecords(body.From, body.To)
+	a.setRedirectRecords(services.ProxyMapping{
+		From:      body.From,
+		To:        body.To,
+		ProxySpec: body.ProxySpec,
+	})
 
 	a.Response(w, nil, http.StatusCreated)
 }
@@ -66,6 +92,9 @@ func (a *App) HandleDeleteProxy(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	a.writes.Lock()
+	defer a.writes.Unlock()
+
 	err := a.Kube.DeleteProxy(a.namespace, body.From)
 	if err != nil {
 		a.Response(w, a.Err("configuration error %s", err), http.StatusInternalServerError)
@@ -89,6 +118,19 @@ func (a *App) HandlePatchProxy(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if errMsg := a.recordError(body.From, body.Cert, body.Key, body.ProxySpec); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := utils.ValidateProxySpec(body.From, body.To, body.ProxySpec); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	a.writes.Lock()
+	defer a.writes.Unlock()
+
 	err := a.Kube.DeleteProxy(a.namespace, body.From)
 	if err != nil {
 		a.Response(w, a.Err("configuration error %s", err), http.StatusInternalServerError)
@@ -97,33 +139,110 @@ func (a *App) HandlePatchProxy(w http.ResponseWriter, req *http.Request) {
 
 	a.deleteRedirectRecords(body.From)
 
-	err = a.Kube.AddNewProxy(body, a.namespace, a.name)
+	err = a.Kube.AddNewProxy(models.AddNewProxy(body), a.namespace, a.name)
 	if err != nil {
 		a.Response(w, a.Err("configuration error: %s", err), http.StatusInternalServerError)
 		return
 	}
 
-	a.setRedirectRecords(body.From, body.To)
+	a.setRedirectRecords(services.ProxyMapping{
+		From:      body.From,
+		To:        body.To,
+		ProxySpec: body.ProxySpec,
+	})
 
 	a.Response(w, nil, http.StatusCreated)
 }
 
+func (a *App) HandlePurgeCache(w http.ResponseWriter, req *http.Request) {
+	var body models.PurgeCache
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		a.Response(w, a.Err("request body decode error %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := utils.ValidateFields(body); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	purged := a.purgeCache(body.From, body.Path)
+	a.Log.Info("Purged cached responses", "host", body.From, "path", body.Path, "purged", purged)
+
+	a.Response(w, models.PurgeCacheResult{Purged: purged}, http.StatusOK)
+}
+
+func (a *App) HandleCanary(w http.ResponseWriter, req *http.Request) {
+	var body models.CanaryAction
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		a.Response(w, a.Err("request body decode error %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := utils.ValidateFields(body); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+	if body.Action != models.CanaryPromote && body.Action != models.CanaryAbort {
+		a.Response(w, a.Err("validation error: unknown canary action %s", body.Action), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.changeCanary(body.From, body.Action); err != nil {
+		a.Response(w, a.Err("canary error: %s", err), http.StatusConflict)
+		return
+	}
+
+	a.Response(w, nil, http.StatusOK)
+}
+
+func (a *App) HandleMaintenance(w http.ResponseWriter, req *http.Request) {
+	var body models.MaintenanceAction
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		a.Response(w, a.Err("request body decode error %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := utils.ValidateFields(body); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+	if errMsg := utils.ValidateMaintenance(&models.Maintenance{RetryAfter: body.RetryAfter}); errMsg != "" {
+		a.Response(w, a.Err("validation error: %s", errMsg), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.changeMaintenance(body); err != nil {
+		a.Response(w, a.Err("maintenance error: %s", err), http.StatusConflict)
+		return
+	}
+
+	a.Response(w, nil, http.StatusOK)
+}
+
 func (a *App) HandleGetRedirectionRecords(w http.ResponseWriter, req *http.Request) {
 
 	records, err := a.getAllRedirectionRecords()
 	if err != nil {
 		a.Response(w, err, http.StatusInternalServerError)
+		return
 	}
 
 	if len(records) < 1 {
 		a.Response(w, a.Err("no redirection records available"), http.StatusNoContent)
+		return
 	}
 
 	var res []models.RedirectionRecords
 	for i, v := range records {
 		record := models.RedirectionRecords{
-			From: i,
-			To:   v,
+			From:        i,
+			To:          v.To,
+			ProxySpec:   v.ProxySpec,
+			Health:      a.Health.Status(i),
+			Breakers:    a.breakerStatus(i),
+			Mirrored:    a.mirrorStatus(v),
+			Certificate: a.certificateStatus(i),
 		}
 		res = append(res, record)
 	}
@@ -5,16 +5,46 @@ import (
 	"strings"
 )
 
+// LoggingMiddleware logs every request together with the real client IP and,
+// once the request is done, the IP access decision taken for it. Requests
+// already seen by an outer LoggingMiddleware are passed through.
 func (a *App) LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestInfoFrom(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		info := &requestInfo{clientIP: a.realClientIP(r)}
+		r = withRequestInfo(r, info)
+
 		if r.Host != "" {
-			a.Log.Info("new request:", "method", r.Method, "path", r.URL.Path, "host", r.Host)
+			a.Log.Info("new request:", "method", r.Method, "path", r.URL.Path, "host", r.Host, "client", info.clientIP)
 		} else if r.Header.Get("X-Forwarded-Host") != "" {
-			a.Log.Info("new request:", "method", r.Method, "path", r.URL.Path, "host", r.Header.Get("X-Forwarded-Host"))
+			a.Log.Info("new request:", "method", r.Method, "path", r.URL.Path, "host", r.Header.Get("X-Forwarded-Host"), "client", info.clientIP)
 		} else {
 			a.Response(w, a.Err("host header and x-forwarded-host header not provided"), http.StatusNoContent)
 		}
 		next.ServeHTTP(w, r)
+
+		switch info.access {
+		case "deny":
+			a.Log.Warn("ip access denied", "client", info.clientIP, "host", r.Host, "rule", info.rule)
+		case "allow":
+			a.Log.Info("ip access allowed", "client", info.clientIP, "host", r.Host, "rule", info.rule)
+		}
+	})
+}
+
+// RateLimitMiddleware applies the global rate limit to proxied traffic.
+func (a *App) RateLimitMiddleware(next http.Handler) http.Handler {
+	if a.globalLimit == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.globalLimit.allow(w, r, "") {
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"strings"
+)
+
+// authUserHeader carries the authenticated user to the upstream.
+const authUserHeader = "X-Auth-User"
+
+// maxAuthBodyBytes caps the forward-auth answer relayed to a rejected client.
+const maxAuthBodyBytes = 64 << 10
+
+// authenticate checks a request against the record's edge authentication
+// and reports whether it may be proxied. Rejected requests are answered
+// with 401 or 403. Identity headers sent by the client are always dropped,
+// so the upstream can trust the ones set here.
+func (h *recordHandler) authenticate(w http.ResponseWriter, req *http.Request) bool {
+	cfg := h.record.Auth
+	req.Header.Del(authUserHeader)
+	for name := range cfg.ClaimHeaders {
+		req.Header.Del(name)
+	}
+	for _, name := range cfg.AuthHeaders {
+		req.Header.Del(name)
+	}
+
+	switch cfg.Type {
+	case models.AuthBasic:
+		return h.authenticateBasic(w, req)
+	case models.AuthJWT:
+		return h.authenticateJWT(w, req)
+	case models.AuthForward:
+		return h.authenticateForward(w, req)
+	}
+	return false
+}
+
+func (h *recordHandler) authenticateBasic(w http.ResponseWriter, req *http.Request) bool {
+	cfg := h.record.Auth
+	challenge := fmt.Sprintf("Basic realm=%q", h.authRealm())
+
+	user, password, ok := req.BasicAuth()
+	if !ok {
+		unauthorized(w, challenge)
+		return false
+	}
+	err := h.app.EdgeAuth.Basic(cfg.Secret, user, password)
+	if errors.Is(err, services.ErrUnauthenticated) {
+		unauthorized(w, challenge)
+		return false
+	}
+	if err != nil {
+		h.app.Response(w, h.app.Err("authentication unavailable for host %s: %s", req.Host, err), http.StatusServiceUnavailable)
+		return false
+	}
+
+	req.Header.Set(authUserHeader, user)
+	return true
+}
+
+func (h *recordHandler) authenticateJWT(w http.ResponseWriter, req *http.Request) bool {
+	cfg := h.record.Auth
+	realm := h.authRealm()
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		unauthorized(w, fmt.Sprintf("Bearer realm=%q", realm))
+		return false
+	}
+	claims, err := h.app.EdgeAuth.JWT(cfg.JWKSURL, cfg.Issuer, cfg.Audience, token)
+	if errors.Is(err, services.ErrUnauthenticated) {
+		unauthorized(w, fmt.Sprintf("Bearer realm=%q, error=\"invalid_token\"", realm))
+		return false
+	}
+	if err != nil {
+		h.app.Response(w, h.app.Err("authentication unavailable for host %s: %s", req.Host, err), http.StatusServiceUnavailable)
+		return false
+	}
+
+	for name, want := range cfg.Claims {
+		if !claimContains(claims[name], want) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=\"insufficient_scope\"", realm))
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return false
+		}
+	}
+
+	if sub, ok := claims["sub"].(string); ok {
+		req.Header.Set(authUserHeader, sub)
+	}
+	for header, claim := range cfg.ClaimHeaders {
+		if v, ok := claims[claim]; ok {
+			req.Header.Set(header, claimString(v))
+		}
+	}
+	return true
+}
+
+// authenticateForward asks the record's auth service about the request. A
+// 2xx answer accepts it; any other answer is relayed to the client, which
+// lets the service reject with 401 or 403 or redirect to a login page.
+func (h *recordHandler) authenticateForward(w http.ResponseWriter, req *http.Request) bool {
+	cfg := h.record.Auth
+
+	sub, err := http.NewRequestWithContext(req.Context(), http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		h.app.Response(w, h.app.Err("invalid forward auth url %s: %s", cfg.URL, err), http.StatusBadGateway)
+		return false
+	}
+	sub.Header = req.Header.Clone()
+	for _, name := range hopHeaders {
+		sub.Header.Del(name)
+	}
+	sub.Header.Del("Content-Length")
+	sub.Header.Set("X-Forwarded-Method", req.Method)
+	sub.Header.Set("X-Forwarded-Proto", requestScheme(req))
+	sub.Header.Set("X-Forwarded-Host", req.Host)
+	sub.Header.Set("X-Forwarded-Uri", req.URL.RequestURI())
+	sub.Header.Set("X-Forwarded-For", clientIP(req))
+
+	res, err := h.app.EdgeAuth.Forward(sub)
+	if err != nil {
+		h.app.Response(w, h.app.Err("authentication unavailable for host %s: %s", req.Host, err), http.StatusServiceUnavailable)
+		return false
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		for _, name := range append([]string{authUserHeader}, cfg.AuthHeaders...) {
+			if v := res.Header.Values(name); len(v) > 0 {
+				req.Header[http.CanonicalHeaderKey(name)] = v
+			}
+		}
+		return true
+	}
+
+	for name, values := range res.Header {
+		w.Header()[name] = values
+	}
+	for _, name := range hopHeaders {
+		w.Header().Del(name)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, io.LimitReader(res.Body, maxAuthBodyBytes))
+	return false
+}
+
+func (h *recordHandler) authRealm() string {
+	if h.record.Auth.Realm != "" {
+		return h.record.Auth.Realm
+	}
+	return h.record.From
+}
+
+func unauthorized(w http.ResponseWriter, challenge string) {
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// claimContains reports whether a claim equals want or, for list claims
+// such as groups or roles, contains it.
+func claimContains(claim any, want string) bool {
+	if list, ok := claim.([]any); ok {
+		for _, v := range list {
+			if claimString(v) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return claim != nil && claimString(claim) == want
+}
+
+func claimString(v any) string {
+	if list, ok := v.([]any); ok {
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			parts = append(parts, claimString(item))
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
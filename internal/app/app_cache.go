@@ -0,0 +1,434 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"prx/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type upstreamHeaderKey struct{}
+
+// upstreamHeader receives the headers of a fetched response as the upstream
+// sent them, before the record's response header rules were applied.
+type upstreamHeader struct {
+	header  http.Header
+	trailer bool
+}
+
+// heuristicStatuses may be cached without explicit freshness information
+// (RFC 9110 section 15.1).
+var heuristicStatuses = []int{200, 203, 204, 300, 301, 308, 404, 405, 410, 414, 501}
+
+// maxHeuristicFreshness caps the freshness derived from Last-Modified.
+const maxHeuristicFreshness = 24 * time.Hour
+
+// serveCached answers a request of a record with caching enabled. Fresh
+// entries are served from the cache, stale ones are revalidated with the
+// stored validators and misses are fetched once for all concurrent callers.
+// Unsafe methods invalidate the stored entry of their target URI.
+func (h *recordHandler) serveCached(w http.ResponseWriter, req *http.Request) {
+	cache := h.app.Cache
+	key := services.CacheKey(req.Host, req.URL.RequestURI())
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		h.serveUpstream(w, req)
+		cache.Remove(key)
+		return
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if req.Header.Get("Range") != "" || reqCC.has("no-store") {
+		h.serveUpstream(w, req)
+		return
+	}
+
+	entry := cache.Get(key, req.Header)
+	if entry != nil && h.fresh(entry, reqCC, time.Now()) {
+		h.serveEntry(w, req, entry)
+		return
+	}
+	if reqCC.has("only-if-cached") {
+		w.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+	if req.Method == http.MethodHead {
+		h.serveUpstream(w, req)
+		return
+	}
+
+	flight, leader := cache.Lead(key)
+	if !leader {
+		flight.Wait(req.Context())
+		if entry := cache.Get(key, req.Header); entry != nil && h.fresh(entry, reqCC, time.Now()) {
+			h.serveEntry(w, req, entry)
+			return
+		}
+		h.serveUpstream(w, req)
+		return
+	}
+	defer cache.Land(key, flight)
+
+	h.fetch(w, req, key, entry)
+}
+
+// fetch sends a cache miss upstream, streaming the response to the client
+// while keeping a copy for the cache. A stale entry is revalidated with its
+// validators; a 304 answer refreshes the entry, which is then served.
+func (h *recordHandler) fetch(w http.ResponseWriter, req *http.Request, key string, stale *services.CacheEntry) {
+	out := req
+	if stale != nil && !hasConditionals(req.Header) && hasConditionals(validators(stale.Header)) {
+		out = req.Clone(req.Context())
+		copyHeader(out.Header, validators(stale.Header))
+	}
+
+	limit := h.record.Cache.MaxObjectBytes
+	if limit <= 0 {
+		limit = services.DefaultCacheMaxObjectBytes
+	}
+	cw := &captureWriter{
+		w:               w,
+		header:          make(http.Header),
+		limit:           limit,
+		holdNotModified: out != req,
+	}
+
+	fetched := &upstreamHeader{}
+	out = out.WithContext(context.WithValue(out.Context(), upstreamHeaderKey{}, fetched))
+
+	requestTime := time.Now()
+	h.serveUpstream(cw, out)
+	responseTime := time.Now()
+
+	// Responses the proxy generated itself are never stored.
+	if fetched.header == nil {
+		return
+	}
+	if cw.held {
+		refreshed := refreshEntry(stale, fetched.header, requestTime, responseTime)
+		h.app.Cache.Put(key, req.Header, refreshed)
+		h.serveEntry(w, req, refreshed)
+		return
+	}
+	if cw.overflow || fetched.trailer || !h.storable(req, cw.status, fetched.header) {
+		return
+	}
+	h.app.Cache.Put(key, req.Header, &services.CacheEntry{
+		Status:       cw.status,
+		Header:       fetched.header,
+		Body:         bytes.Clone(cw.buf.Bytes()),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	})
+}
+
+// storable implements the storage rules of a shared cache (RFC 9111
+// section 3). Responses setting cookies or trailers are never stored.
+func (h *recordHandler) storable(req *http.Request, status int, header http.Header) bool {
+	if status < 200 || status == http.StatusPartialContent || status == http.StatusNotModified {
+		return false
+	}
+	cc := parseCacheControl(header)
+	if cc.has("no-store") || cc.has("private") {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+		return false
+	}
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	explicit := cc.has("public") || cc.has("max-age") || cc.has("s-maxage") || header.Get("Expires") != ""
+	if !explicit && !slices.Contains(heuristicStatuses, status) {
+		return false
+	}
+
+	probe := &services.CacheEntry{Status: status, Header: header}
+	return h.freshnessLifetime(probe) > 0 || header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// fresh reports whether an entry may be served without revalidation, taking
+// the request's Cache-Control directives into account.
+func (h *recordHandler) fresh(e *services.CacheEntry, reqCC cacheControl, now time.Time) bool {
+	if reqCC.has("no-cache") || parseCacheControl(e.Header).has("no-cache") {
+		return false
+	}
+
+	lifetime := h.freshnessLifetime(e)
+	age := currentAge(e, now)
+	if maxAge, ok := reqCC.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := reqCC.seconds("min-fresh"); ok {
+		lifetime -= minFresh
+	}
+	if age < lifetime {
+		return true
+	}
+
+	respCC := parseCacheControl(e.Header)
+	if v, ok := reqCC["max-stale"]; ok && !respCC.has("must-revalidate") && !respCC.has("proxy-revalidate") && !respCC.has("s-maxage") {
+		if v == "" {
+			return true
+		}
+		maxStale, ok := reqCC.seconds("max-stale")
+		return ok && age-lifetime <= maxStale
+	}
+	return false
+}
+
+// freshnessLifetime follows RFC 9111 section 4.2.1: s-maxage, max-age,
+// Expires and finally a heuristic from the record's defaultTTL or from
+// Last-Modified.
+func (h *recordHandler) freshnessLifetime(e *services.CacheEntry) time.Duration {
+	cc := parseCacheControl(e.Header)
+	if d, ok := cc.seconds("s-maxage"); ok {
+		return d
+	}
+	if d, ok := cc.seconds("max-age"); ok {
+		return d
+	}
+	if v := e.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+		return expires.Sub(responseDate(e))
+	}
+
+	if !slices.Contains(heuristicStatuses, e.Status) {
+		return 0
+	}
+	if ttl, err := time.ParseDuration(h.record.Cache.DefaultTTL); err == nil {
+		return ttl
+	}
+	if lm, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil {
+		if since := responseDate(e).Sub(lm); since > 0 {
+			return min(since/10, maxHeuristicFreshness)
+		}
+	}
+	return 0
+}
+
+// currentAge follows RFC 9111 section 4.2.3.
+func currentAge(e *services.CacheEntry, now time.Time) time.Duration {
+	var ageValue time.Duration
+	if secs, err := strconv.Atoi(e.Header.Get("Age")); err == nil && secs > 0 {
+		ageValue = time.Duration(secs) * time.Second
+	}
+	apparentAge := max(e.ResponseTime.Sub(responseDate(e)), 0)
+	correctedAge := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparentAge, correctedAge) + now.Sub(e.ResponseTime)
+}
+
+func responseDate(e *services.CacheEntry) time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// refreshEntry applies the headers of a 304 answer to a stored entry
+// (RFC 9111 section 4.3.4).
+func refreshEntry(e *services.CacheEntry, header http.Header, requestTime, responseTime time.Time) *services.CacheEntry {
+	refreshed := &services.CacheEntry{
+		Status:       e.Status,
+		Header:       e.Header.Clone(),
+		Body:         e.Body,
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	for k, v := range header {
+		switch k {
+		case "Content-Length", "Content-Encoding", "Content-Range", "Transfer-Encoding":
+			continue
+		}
+		refreshed.Header[k] = v
+	}
+	return refreshed
+}
+
+// serveEntry writes a stored response, answering the client's own
+// conditional request with 304 when the entry still matches.
+func (h *recordHandler) serveEntry(w http.ResponseWriter, req *http.Request, e *services.CacheEntry) {
+	header := w.Header()
+	for k, v := range e.Header {
+		header[k] = slices.Clone(v)
+	}
+	header.Set("Age", strconv.Itoa(int(currentAge(e, time.Now()).Seconds())))
+	h.cors.apply(req, header)
+	h.responseHeaders.apply(header, headerVarsFrom(req.Context()))
+
+	if e.Status == http.StatusOK && notModified(req.Header, e.Header) {
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(e.Status)
+	if req.Method != http.MethodHead {
+		w.Write(e.Body)
+	}
+}
+
+func notModified(reqHeader, header http.Header) bool {
+	if inm := reqHeader.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(reqHeader.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(header.Get("Last-Modified"))
+	return err == nil && !lm.After(ims)
+}
+
+// validators turns the validators of a stored response into the headers of
+// a conditional request.
+func validators(header http.Header) http.Header {
+	cond := http.Header{}
+	if etag := header.Get("ETag"); etag != "" {
+		cond.Set("If-None-Match", etag)
+	}
+	if lm := header.Get("Last-Modified"); lm != "" {
+		cond.Set("If-Modified-Since", lm)
+	}
+	return cond
+}
+
+func hasConditionals(header http.Header) bool {
+	return header.Get("If-None-Match") != "" || header.Get("If-Modified-Since") != "" ||
+		header.Get("If-Match") != "" || header.Get("If-Unmodified-Since") != ""
+}
+
+// cacheControl holds the directives of a Cache-Control header, keyed by
+// lower case name. Directives without an argument map to "".
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, v := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			cc[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// captureWriter streams a response to the client while keeping a copy of up
+// to limit bytes for the cache. When holdNotModified is set, a 304 answer to
+// the proxy's own revalidation is held back instead of being forwarded.
+type captureWriter struct {
+	w               http.ResponseWriter
+	header          http.Header
+	limit           int64
+	holdNotModified bool
+
+	wroteHeader bool
+	held        bool
+	overflow    bool
+	status      int
+	buf         bytes.Buffer
+}
+
+func (c *captureWriter) Header() http.Header {
+	if c.wroteHeader && !c.held {
+		// Trailers are set after the header was sent and belong to the client.
+		return c.w.Header()
+	}
+	return c.header
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	if c.wroteHeader {
+		return
+	}
+	if code >= 100 && code < 200 {
+		copyHeader(c.w.Header(), c.header)
+		c.w.WriteHeader(code)
+		return
+	}
+
+	c.wroteHeader = true
+	c.status = code
+	if code == http.StatusNotModified && c.holdNotModified {
+		c.held = true
+		return
+	}
+	copyHeader(c.w.Header(), c.header)
+	c.w.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.held {
+		return len(p), nil
+	}
+	if !c.overflow {
+		if int64(c.buf.Len()+len(p)) > c.limit {
+			c.overflow = true
+			c.buf = bytes.Buffer{}
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return c.w.Write(p)
+}
+
+func (c *captureWriter) Flush() {
+	if c.held {
+		return
+	}
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(c.w).Flush()
+}
+
+func (c *captureWriter) Unwrap() http.ResponseWriter {
+	return c.w
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = slices.Clone(v)
+	}
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"prx/internal/models"
+	"prx/internal/services"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Sticky canary cookie defaults.
+const (
+	defaultCanaryCookie = "prx_canary"
+	canaryCookieMaxAge  = 30 * 24 * time.Hour
+)
+
+// canaryRouter is the compiled canary of a record.
+type canaryRouter struct {
+	target *url.URL
+	cfg    models.Canary
+	cookie string
+}
+
+func newCanaryRouter(cfg models.Canary) (*canaryRouter, error) {
+	target, err := url.Parse(cfg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canary target %s: %v", cfg.To, err)
+	}
+	cookie := cfg.StickyCookie
+	if cookie == "" {
+		cookie = defaultCanaryCookie
+	}
+	return &canaryRouter{target: target, cfg: cfg, cookie: cookie}, nil
+}
+
+// pick reports whether a request goes to the canary. Clients split by
+// weight get a random bucket from 0 to 99 in the sticky cookie on their
+// first request and go to the canary while their bucket is below the weight,
+// so raising the weight only ever moves clients onto the canary.
+func (c *canaryRouter) pick(w http.ResponseWriter, req *http.Request) bool {
+	for _, m := range c.cfg.Matches {
+		if canaryMatches(m, req) {
+			return true
+		}
+	}
+	if c.cfg.Weight <= 0 {
+		return false
+	}
+
+	bucket := -1
+	if cookie, err := req.Cookie(c.cookie); err == nil {
+		if n, err := strconv.Atoi(cookie.Value); err == nil && n >= 0 && n < 100 {
+			bucket = n
+		}
+	}
+	if bucket < 0 {
+		bucket = rand.IntN(100)
+		http.SetCookie(w, &http.Cookie{
+			Name:     c.cookie,
+			Value:    strconv.Itoa(bucket),
+			Path:     "/",
+			MaxAge:   int(canaryCookieMaxAge.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return bucket < c.cfg.Weight
+}
+
+func canaryMatches(m models.CanaryMatch, req *http.Request) bool {
+	var values []string
+	switch {
+	case m.Header != "":
+		values = req.Header.Values(m.Header)
+	case m.Cookie != "":
+		for _, cookie := range req.CookiesNamed(m.Cookie) {
+			values = append(values, cookie.Value)
+		}
+	case m.Query != "":
+		values = req.URL.Query()[m.Query]
+	}
+	if m.Value == "" {
+		return len(values) > 0
+	}
+	return slices.Contains(values, m.Value)
+}
+
+// changeCanary promotes or aborts the canary of a record in one step.
+// Promoting makes the canary target the record's only target; aborting
+// drops the canary and leaves the record as it was before. The change is
+// made to the record as stored in the cluster and only served once stored.
+func (a *App) changeCanary(from, action string) error {
+	if action != models.CanaryPromote && action != models.CanaryAbort {
+		return fmt.Errorf("unknown canary action %s", action)
+	}
+
+	a.writes.Lock()
+	defer a.writes.Unlock()
+
+	if _, _, err := a.getRedirectionRecords(from); err != nil {
+		return err
+	}
+	record, err := a.Kube.ModifyProxyMapping(a.namespace, a.name, from, func(record *services.ProxyMapping) error {
+		if record.Canary == nil {
+			return fmt.Errorf("record %s has no canary", from)
+		}
+		if action == models.CanaryPromote {
+			record.To = record.Canary.To
+			record.Upstreams = nil
+			record.Policy = ""
+			record.HashHeader = ""
+			record.Affinity = nil
+		}
+		record.Canary = nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.RedirectRecords[from] = record
+	a.resetRecordState(from)
+	a.mu.Unlock()
+
+	if record.HealthCheck != nil {
+		a.Health.Watch(record.From, upstreamsOf(record), *record.HealthCheck, a.upstreamTransport(record))
+	}
+	a.Log.Info("Changed canary", "host", from, "action", action, "to", record.To)
+	return nil
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/utils"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultCORSMethods are allowed when a policy lists no methods, the same as
+// the CORS-safelisted methods.
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// corsPolicy is the compiled CORS policy of a record.
+type corsPolicy struct {
+	cfg       models.CORS
+	anyOrigin bool
+	origins   []*regexp.Regexp
+	methods   []string
+	headers   []string
+}
+
+// newCORSPolicy compiles the origin patterns of a policy. "*" allows every
+// origin, a "~" prefix marks a regular expression and any other "*" stands
+// for one or more characters up to the next "." or ":".
+func newCORSPolicy(cfg *models.CORS) (*corsPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	p := &corsPolicy{cfg: *cfg, methods: defaultCORSMethods}
+	for _, o := range cfg.AllowOrigins {
+		if o == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		re, err := compileOriginPattern(o)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cors origin %s: %v", o, err)
+		}
+		p.origins = append(p.origins, re)
+	}
+	if len(cfg.AllowMethods) > 0 {
+		p.methods = nil
+		for _, m := range cfg.AllowMethods {
+			p.methods = append(p.methods, strings.ToUpper(m))
+		}
+	}
+	for _, h := range cfg.AllowHeaders {
+		p.headers = append(p.headers, strings.ToLower(h))
+	}
+	return p, nil
+}
+
+func compileOriginPattern(origin string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(origin, utils.RegexHostPrefix); ok {
+		return regexp.Compile("^(?:" + expr + ")$")
+	}
+	parts := strings.Split(strings.ToLower(origin), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.Compile("^" + strings.Join(parts, "[^.:/]+") + "$")
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, re := range p.origins {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreflight reports whether req is a CORS preflight rather than an OPTIONS
+// request meant for the upstream.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// servePreflight answers a preflight without contacting the upstream. A
+// preflight for an origin, method or header outside the policy gets a 403
+// without CORS headers, which the browser reports as a failed check.
+func (p *corsPolicy) servePreflight(w http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	method := strings.ToUpper(req.Header.Get("Access-Control-Request-Method"))
+	requested := splitHeaderList(req.Header.Values("Access-Control-Request-Headers"))
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	if !p.allowOrigin(origin) || !p.allowMethod(method) || !p.allowHeaders(requested) {
+		http.Error(w, "cors preflight rejected", http.StatusForbidden)
+		return
+	}
+
+	p.setOrigin(header, origin)
+	if slices.Contains(p.methods, "*") {
+		header.Set("Access-Control-Allow-Methods", method)
+	} else {
+		header.Set("Access-Control-Allow-Methods", strings.Join(p.methods, ", "))
+	}
+	if len(requested) > 0 {
+		// The requested headers passed allowHeaders, so echoing them never
+		// grants more than the policy does.
+		header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if p.cfg.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(p.cfg.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply adds the CORS headers of an actual request to its response,
+// replacing any the upstream sent.
+func (p *corsPolicy) apply(req *http.Request, header http.Header) {
+	if p == nil {
+		return
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	for name := range header {
+		if strings.HasPrefix(name, "Access-Control-") {
+			delete(header, name)
+		}
+	}
+	if !p.anyOrigin || p.cfg.AllowCredentials {
+		header.Add("Vary", "Origin")
+	}
+	if !p.allowOrigin(origin) {
+		return
+	}
+	p.setOrigin(header, origin)
+	if len(p.cfg.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(p.cfg.ExposeHeaders, ", "))
+	}
+}
+
+// setOrigin allows origin. Credentialed responses must name the origin, so
+// "*" is only sent back without credentials.
+func (p *corsPolicy) setOrigin(header http.Header, origin string) {
+	if p.anyOrigin && !p.cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *corsPolicy) allowMethod(method string) bool {
+	return slices.Contains(p.methods, "*") || slices.Contains(p.methods, method) ||
+		slices.Contains(defaultCORSMethods, method)
+}
+
+// allowHeaders reports whether every requested header is allowed. A policy
+// without allowed headers accepts whatever the client asks for.
+func (p *corsPolicy) allowHeaders(requested []string) bool {
+	if len(p.headers) == 0 || slices.Contains(p.headers, "*") {
+		return true
+	}
+	for _, h := range requested {
+		if !slices.Contains(p.headers, strings.ToLower(h)) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitHeaderList(values []string) []string {
+	var res []string
+	for _, v := range values {
+		for _, h := range strings.Split(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				res = append(res, h)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"strconv"
+	"time"
+)
+
+// defaultMaintenanceRetryAfter is sent when a record in maintenance does not
+// say when to come back.
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// serveErrorPage answers with the error page for status from configMap or
+// the global default and reports whether one was found. key is tried before
+// the status, see services.ErrorPages.Render.
+func (a *App) serveErrorPage(w http.ResponseWriter, req *http.Request, configMap, key string, status int, message string) bool {
+	if a.ErrorPages == nil {
+		return false
+	}
+	retryAfter, _ := strconv.Atoi(w.Header().Get("Retry-After"))
+	contentType, body, ok := a.ErrorPages.Render(configMap, key, req.Header.Get("Accept"), services.ErrorPageData{
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Host:       req.Host,
+		Path:       req.URL.Path,
+		Message:    message,
+		RequestID:  req.Header.Get("X-Request-Id"),
+		RetryAfter: retryAfter,
+	})
+	if !ok {
+		return false
+	}
+
+	header := w.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Type", contentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if req.Method != http.MethodHead {
+		w.Write(body)
+	}
+	return true
+}
+
+// respondError answers with the error page for status when there is one and
+// with the usual JSON error otherwise.
+func (a *App) respondError(w http.ResponseWriter, req *http.Request, configMap string, err error, status int) {
+	if a.serveErrorPage(w, req, configMap, "", status, err.Error()) {
+		a.Log.Error(err.Error())
+		return
+	}
+	a.Response(w, err, status)
+}
+
+// serveMaintenance answers a request of a record in maintenance mode.
+func (h *recordHandler) serveMaintenance(w http.ResponseWriter, req *http.Request) {
+	m := h.record.Maintenance
+	retryAfter := defaultMaintenanceRetryAfter
+	if d, err := time.ParseDuration(m.RetryAfter); err == nil {
+		retryAfter = d
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+
+	message := m.Message
+	if message == "" {
+		message = "service under maintenance"
+	}
+	if !h.app.serveErrorPage(w, req, h.record.ErrorPages, services.MaintenancePage, http.StatusServiceUnavailable, message) {
+		http.Error(w, message, http.StatusServiceUnavailable)
+	}
+}
+
+// changeMaintenance switches maintenance mode of a record on or off. An
+// empty retryAfter or message keeps the one already configured. The change
+// is made to the record as stored in the cluster and only served once
+// stored.
+func (a *App) changeMaintenance(action models.MaintenanceAction) error {
+	a.writes.Lock()
+	defer a.writes.Unlock()
+
+	if _, _, err := a.getRedirectionRecords(action.From); err != nil {
+		return err
+	}
+	record, err := a.Kube.ModifyProxyMapping(a.namespace, a.name, action.From, func(record *services.ProxyMapping) error {
+		m := models.Maintenance{}
+		if record.Maintenance != nil {
+			m = *record.Maintenance
+		}
+		m.Enabled = action.Enabled
+		if action.RetryAfter != "" {
+			m.RetryAfter = action.RetryAfter
+		}
+		if action.Message != "" {
+			m.Message = action.Message
+		}
+		record.Maintenance = &m
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.RedirectRecords[action.From] = record
+	a.resetRecordState(action.From)
+	a.mu.Unlock()
+
+	a.Log.Info("Changed maintenance mode", "host", action.From, "enabled", action.Enabled)
+	return nil
+}
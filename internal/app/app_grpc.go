@@ -0,0 +1,102 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// isGRPCRequest reports whether req is a native gRPC call. gRPC-Web calls
+// read their status from the body and are left alone.
+func isGRPCRequest(req *http.Request) bool {
+	ct := req.Header.Get("Content-Type")
+	return req.ProtoMajor == 2 && (ct == "application/grpc" ||
+		strings.HasPrefix(ct, "application/grpc+") || strings.HasPrefix(ct, "application/grpc;"))
+}
+
+// GRPCErrorMiddleware lets gRPC clients see the errors prx answers itself,
+// such as an unreachable upstream or a rate limit, as gRPC statuses. gRPC
+// clients do not read HTTP error bodies, so those answers are turned into
+// trailers-only responses carrying grpc-status and grpc-message.
+func (a *App) GRPCErrorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isGRPCRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&grpcErrorWriter{ResponseWriter: w}, r)
+	})
+}
+
+// grpcErrorWriter rewrites responses that are not gRPC responses. Answers
+// of the upstream carry a gRPC content type and pass through untouched,
+// trailers and flushes included.
+type grpcErrorWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	discard     bool
+}
+
+func (g *grpcErrorWriter) WriteHeader(code int) {
+	if g.wroteHeader {
+		return
+	}
+	if code < http.StatusOK {
+		g.ResponseWriter.WriteHeader(code)
+		return
+	}
+	g.wroteHeader = true
+
+	header := g.Header()
+	if code == http.StatusOK || strings.HasPrefix(header.Get("Content-Type"), "application/grpc") {
+		g.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	g.discard = true
+	for _, name := range []string{"Content-Length", "Content-Encoding", "X-Content-Type-Options"} {
+		header.Del(name)
+	}
+	header.Set("Content-Type", "application/grpc")
+	header.Set("Grpc-Status", strconv.Itoa(int(grpcCodeOf(code))))
+	header.Set("Grpc-Message", http.StatusText(code))
+	g.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+func (g *grpcErrorWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.discard {
+		return len(p), nil
+	}
+	return g.ResponseWriter.Write(p)
+}
+
+func (g *grpcErrorWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// grpcCodeOf maps an HTTP status to a gRPC code, following the gRPC mapping
+// for proxies except where prx knows better what the status means.
+func grpcCodeOf(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
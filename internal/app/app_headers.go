@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"prx/internal/models"
+	"sort"
+	"strings"
+)
+
+type headerVarsKey struct{}
+
+// headerVars are the values header rules can refer to as {client_ip},
+// {request_id}, {host}, {method}, {path} and {scheme}. They are taken from the
+// client's request before any path rule rewrote it.
+type headerVars struct {
+	replacer *strings.Replacer
+}
+
+func newHeaderVars(req *http.Request) *headerVars {
+	requestID := req.Header.Get("X-Request-Id")
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	return &headerVars{replacer: strings.NewReplacer(
+		"{client_ip}", clientIP(req),
+		"{request_id}", requestID,
+		"{host}", req.Host,
+		"{method}", req.Method,
+		"{path}", req.URL.Path,
+		"{scheme}", requestScheme(req),
+	)}
+}
+
+func headerVarsFrom(ctx context.Context) *headerVars {
+	vars, _ := ctx.Value(headerVarsKey{}).(*headerVars)
+	return vars
+}
+
+func (v *headerVars) expand(value string) string {
+	if v == nil || !strings.Contains(value, "{") {
+		return value
+	}
+	return v.replacer.Replace(value)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type headerValue struct {
+	name  string
+	value string
+}
+
+// headerRules is the compiled form of models.HeaderRules with canonical
+// names in a stable order.
+type headerRules struct {
+	remove []string
+	set    []headerValue
+	add    []headerValue
+}
+
+func compileHeaderRules(r *models.HeaderRules) *headerRules {
+	if r == nil {
+		return nil
+	}
+	rules := &headerRules{
+		set: sortedHeaderValues(r.Set),
+		add: sortedHeaderValues(r.Add),
+	}
+	for _, name := range r.Remove {
+		rules.remove = append(rules.remove, http.CanonicalHeaderKey(name))
+	}
+	return rules
+}
+
+func sortedHeaderValues(m map[string]string) []headerValue {
+	var res []headerValue
+	for name, value := range m {
+		res = append(res, headerValue{name: http.CanonicalHeaderKey(name), value: value})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
+
+// apply removes, then sets, then adds headers.
+func (r *headerRules) apply(h http.Header, vars *headerVars) {
+	if r == nil {
+		return
+	}
+	for _, name := range r.remove {
+		h.Del(name)
+	}
+	for _, hv := range r.set {
+		h.Set(hv.name, vars.expand(hv.value))
+	}
+	for _, hv := range r.add {
+		h.Add(hv.name, vars.expand(hv.value))
+	}
+}
+
+// applyRequest applies request rules to an outbound request. A Host header
+// set by a rule overrides the Host sent upstream.
+func (r *headerRules) applyRequest(out *http.Request, vars *headerVars) {
+	if r == nil {
+		return
+	}
+	r.apply(out.Header, vars)
+	if host := out.Header.Get("Host"); host != "" {
+		out.Host = host
+		out.Header.Del("Host")
+	}
+}
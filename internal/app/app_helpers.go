@@ -39,35 +39,41 @@ func (a *App) Response(w http.ResponseWriter, data interface{}, statusCode int)
 	}
 }
 
-func (a *App) getRedirectionRecords(host string) (string, error) {
-	var targetURL string
+func (a *App) getRedirectionRecords(host string) (services.ProxyMapping, []string, error) {
+	var record services.ProxyMapping
+	var labels []string
 	var ok bool
 
-	targetURL, ok = a.readRedirectRecord(host)
+	record, labels, ok = a.readRedirectRecord(host)
 	if !ok {
 		a.Log.Warn("No redirect record found in memory for host:", "host", host)
 		var err error
 		redirectRecords, err := a.Kube.GetProxyMappings(a.namespace, a.name)
 		if err != nil {
 			a.Log.Error("Error getting redirect records from cluster", "err", err)
-			return "", fmt.Errorf("no redirect records found in cluster for host %s", host)
+			return record, nil, fmt.Errorf("no redirect records found in cluster for host %s", host)
 		}
 
-		targetURL, ok = redirectRecords[host]
+		record, labels, ok = lookupRecord(redirectRecords, newHostIndex(redirectRecords), host)
 		if !ok {
 			a.Log.Error("No redirect records found in cluster for host:", "host", host)
-			return "", fmt.Errorf("no redirect records found in cluster for host %s", host)
+			return record, nil, fmt.Errorf("no redirect records found in cluster for host %s", host)
 		}
 
-		a.setRedirectRecordsInMemory(host, targetURL)
+		a.setRedirectRecordsInMemory(record)
 	}
 
-	return targetURL, nil
+	return record, labels, nil
 }
 
-func (a *App) getAllRedirectionRecords() (map[string]string, error) {
+func (a *App) getAllRedirectionRecords() (map[string]services.ProxyMapping, error) {
 
-	res := a.RedirectRecords
+	a.mu.Lock()
+	res := make(map[string]services.ProxyMapping, len(a.RedirectRecords))
+	for from, record := range a.RedirectRecords {
+		res[from] = record
+	}
+	a.mu.Unlock()
 
 	if len(res) < 1 {
 		redirectRecords, err := a.Kube.GetProxyMappings(a.namespace, a.name)
@@ -90,53 +96,143 @@ func (a *App) printSettings(token, secret string) {
 	a.Log.Info("=====================================")
 }
 
-func (a *App) readRedirectRecord(host string) (string, bool) {
+// loadRedirectRecords warms the in-memory records from the cluster so health
+// checks run for every record and not only for hosts that saw traffic.
+func (a *App) loadRedirectRecords() {
+	redirectRecords, err := a.Kube.GetProxyMappings(a.namespace, a.name)
+	if err != nil {
+		a.Log.Warn("Could not load redirect records from cluster on startup", "err", err)
+		return
+	}
+
+	for _, record := range redirectRecords {
+		a.setRedirectRecordsInMemory(record)
+	}
+	a.Log.Info("Loaded redirect records from cluster", "count", len(redirectRecords))
+}
+
+func (a *App) readRedirectRecord(host string) (services.ProxyMapping, []string, bool) {
 	a.mu.Lock()
-	targetURL, ok := a.RedirectRecords[host]
+	record, labels, ok := lookupRecord(a.RedirectRecords, a.hosts, host)
 	a.mu.Unlock()
 
-	return targetURL, ok
+	return record, labels, ok
 }
 
 func (a *App) deleteRedirectRecords(host string) {
 	a.deleteRedirectRecordsInMemory(host)
 	a.deleteRedirectRecordsInCluster(host)
+	a.refreshCerts()
 }
 
 func (a *App) deleteRedirectRecordsInMemory(host string) {
 	a.mu.Lock()
 	delete(a.RedirectRecords, host)
+	a.hosts.remove(host)
+	a.resetRecordState(host)
+	a.closeTCPListener(host)
 	a.mu.Unlock()
+
+	a.Health.Unwatch(host)
+	a.Mirror.Forget(host)
+	if a.ACME != nil {
+		a.ACME.Forget(host)
+	}
 }
 
 func (a *App) deleteRedirectRecordsInCluster(host string) {
-	a.Kube.DeleteProxy(a.namespace, host)
+	if err := a.Kube.DeleteProxyMapping(a.namespace, a.name, host); err != nil {
+		a.Log.Warn("Failed to delete redirect record from cluster", "host", host, "err", err)
+	}
 }
 
-func (a *App) setRedirectRecords(from, to string) {
-	a.setRedirectRecordsInMemory(from, to)
-	a.setRedirectRecordsInCluster(from, to)
+func (a *App) setRedirectRecords(record services.ProxyMapping) {
+	a.setRedirectRecordsInMemory(record)
+	if err := a.setRedirectRecordsInCluster(record); err != nil {
+		a.Log.Error("Failed to store redirect record in cluster", "host", record.From, "err", err)
+	}
+	a.refreshCerts()
 }
 
-func (a *App) setRedirectRecordsInMemory(from, to string) {
+func (a *App) setRedirectRecordsInMemory(record services.ProxyMapping) {
 	a.mu.Lock()
-	if _, ok := a.RedirectRecords[from]; !ok {
-		a.RedirectRecords[from] = to
+	_, exists := a.RedirectRecords[record.From]
+	if !exists {
+		a.RedirectRecords[record.From] = record
+		a.hosts.add(record.From)
+		a.resetRecordState(record.From)
 	}
 	a.mu.Unlock()
+
+	if !exists && record.HealthCheck != nil {
+		a.Health.Watch(record.From, upstreamsOf(record), *record.HealthCheck, a.upstreamTransport(record))
+	}
+	if !exists && a.ACME != nil && !isL4(record.Protocol) {
+		a.ACME.Manage(record.From)
+	}
+	if !exists && record.Protocol == models.ProtocolTCP {
+		a.listenTCP(record)
+	}
+}
+
+// breakerStatus returns the circuit breaker states of a record that has seen
+// traffic since it was last changed.
+func (a *App) breakerStatus(host string) []models.BreakerStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var res []models.BreakerStatus
+	for _, h := range a.handlers {
+		if h.record.From == host && h.breakers != nil {
+			res = append(res, h.breakers.Status()...)
+		}
+	}
+	return res
+}
+
+// mirrorStatus returns the mirror counters of a record that mirrors traffic.
+func (a *App) mirrorStatus(record services.ProxyMapping) *models.MirrorStatus {
+	if record.Mirror == nil {
+		return nil
+	}
+	status := a.Mirror.Status(record.From)
+	return &status
+}
+
+// resetRecordState drops the cached handlers and the cached responses of a
+// record so it is rebuilt from the current record on the next request.
+// Callers must hold a.mu.
+func (a *App) resetRecordState(host string) {
+	for key, h := range a.handlers {
+		if h.record.From == host {
+			a.dropHandler(key)
+		}
+	}
+	a.purgeCache(host, "")
+}
+
+// dropHandler removes a cached handler and closes the idle connections of
+// its own transport. Callers must hold a.mu.
+func (a *App) dropHandler(key string) {
+	h, ok := a.handlers[key]
+	if !ok {
+		return
+	}
+	delete(a.handlers, key)
+	a.labeledHandlers.remove(key)
+	if h.transport != nil && h.transport != a.Transport {
+		h.transport.CloseIdleConnections()
+	}
 }
 
-func (a *App) setRedirectRecordsInCluster(from, to string) error {
+func (a *App) setRedirectRecordsInCluster(record services.ProxyMapping) error {
 	list, err := a.Kube.GetProxyMappings(a.namespace, a.name)
 	if err != nil {
 		return err
 	}
 
-	if _, ok := list[from]; !ok {
-		err = a.Kube.AddProxyMapping(a.namespace, a.name, services.ProxyMapping{
-			From: from,
-			To:   to,
-		})
+	if _, ok := list[record.From]; !ok {
+		err = a.Kube.AddProxyMapping(a.namespace, a.name, record)
 	}
 	return err
 }
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"container/list"
+	"prx/internal/models"
+	"prx/internal/services"
+	"prx/internal/utils"
+	"sort"
+	"strings"
+)
+
+// hostIndex holds the wildcard and regex records in match order: wildcards
+// before regexes, the longest wildcard suffix first and regexes by from.
+// Exact hosts are looked up in the record map and never land here.
+type hostIndex struct {
+	patterns []*utils.HostPattern
+}
+
+func newHostIndex(records map[string]services.ProxyMapping) *hostIndex {
+	x := &hostIndex{}
+	for from := range records {
+		x.add(from)
+	}
+	return x
+}
+
+func (x *hostIndex) add(from string) {
+	pattern, err := utils.ParseHostPattern(from)
+	if err != nil || pattern == nil {
+		return
+	}
+	x.remove(from)
+	x.patterns = append(x.patterns, pattern)
+	sort.SliceStable(x.patterns, func(i, j int) bool {
+		a, b := x.patterns[i], x.patterns[j]
+		if a.IsRegex() != b.IsRegex() {
+			return !a.IsRegex()
+		}
+		if a.Specificity() != b.Specificity() {
+			return a.Specificity() > b.Specificity()
+		}
+		return a.From < b.From
+	})
+}
+
+func (x *hostIndex) remove(from string) {
+	for i, p := range x.patterns {
+		if p.From == from {
+			x.patterns = append(x.patterns[:i], x.patterns[i+1:]...)
+			return
+		}
+	}
+}
+
+// match returns the from of the first pattern matching host together with
+// the matched labels.
+func (x *hostIndex) match(host string) (string, []string, bool) {
+	for _, p := range x.patterns {
+		if labels, ok := p.Match(host); ok {
+			return p.From, labels, true
+		}
+	}
+	return "", nil, false
+}
+
+// lookupRecord finds the record of a request host: an exact record first,
+// then a wildcard record, then a regex record.
+func lookupRecord(records map[string]services.ProxyMapping, hosts *hostIndex, host string) (services.ProxyMapping, []string, bool) {
+	if record, ok := records[host]; ok {
+		return record, nil, true
+	}
+	from, labels, ok := hosts.match(host)
+	if !ok {
+		return services.ProxyMapping{}, nil, false
+	}
+	record, ok := records[from]
+	return record, labels, ok
+}
+
+// hostLabelsUsed reports whether the targets of a record depend on the
+// labels of the request host.
+func hostLabelsUsed(record services.ProxyMapping) bool {
+	if utils.HasHostLabels(record.To) {
+		return true
+	}
+	for _, u := range record.Upstreams {
+		if utils.HasHostLabels(u.URL) {
+			return true
+		}
+	}
+	for _, rule := range record.Routes {
+		if utils.HasHostLabels(rule.To) {
+			return true
+		}
+	}
+	if record.Canary != nil && utils.HasHostLabels(record.Canary.To) {
+		return true
+	}
+	return record.Mirror != nil && utils.HasHostLabels(record.Mirror.URL)
+}
+
+// expandRecord returns a copy of record with the {N} placeholders of its
+// targets replaced by the matched host labels.
+func expandRecord(record services.ProxyMapping, labels []string) services.ProxyMapping {
+	record.To = utils.ExpandHostLabels(record.To, labels)
+
+	upstreams := make([]models.Upstream, 0, len(record.Upstreams))
+	for _, u := range record.Upstreams {
+		u.URL = utils.ExpandHostLabels(u.URL, labels)
+		upstreams = append(upstreams, u)
+	}
+	record.Upstreams = upstreams
+
+	routes := make([]models.PathRule, 0, len(record.Routes))
+	for _, rule := range record.Routes {
+		rule.To = utils.ExpandHostLabels(rule.To, labels)
+		routes = append(routes, rule)
+	}
+	record.Routes = routes
+
+	if record.Mirror != nil {
+		mirror := *record.Mirror
+		mirror.URL = utils.ExpandHostLabels(mirror.URL, labels)
+		record.Mirror = &mirror
+	}
+
+	if record.Canary != nil {
+		canary := *record.Canary
+		canary.To = utils.ExpandHostLabels(canary.To, labels)
+		record.Canary = &canary
+	}
+
+	return record
+}
+
+// handlerKey is the key of a record handler. Records whose targets use host
+// labels get a handler per distinct set of labels.
+func handlerKey(record services.ProxyMapping, labels []string) string {
+	if len(labels) == 0 || !hostLabelsUsed(record) {
+		return record.From
+	}
+	return record.From + "\x00" + strings.Join(labels, "\x00")
+}
+
+// defaultLabeledHandlers is how many handlers are kept for records whose
+// targets use host labels, unless PRX_LABELED_HANDLERS_MAX says otherwise.
+const defaultLabeledHandlers = 1024
+
+// handlerLRU orders the handler keys of records whose targets use host
+// labels by last use. Each distinct set of labels gets a handler with its
+// own balancer, breakers and transport, so without a bound clients asking for
+// random subdomains would grow them forever. Callers must hold a.mu.
+type handlerLRU struct {
+	max   int
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newHandlerLRU(max int) *handlerLRU {
+	return &handlerLRU{max: max, order: list.New(), elems: make(map[string]*list.Element)}
+}
+
+// touch marks key as just used and returns the least recently used keys
+// that no longer fit.
+func (l *handlerLRU) touch(key string) []string {
+	if el, ok := l.elems[key]; ok {
+		l.order.MoveToFront(el)
+		return nil
+	}
+	l.elems[key] = l.order.PushFront(key)
+
+	var evicted []string
+	for l.order.Len() > l.max {
+		el := l.order.Back()
+		l.order.Remove(el)
+		delete(l.elems, el.Value.(string))
+		evicted = append(evicted, el.Value.(string))
+	}
+	return evicted
+}
+
+func (l *handlerLRU) remove(key string) {
+	if el, ok := l.elems[key]; ok {
+		l.order.Remove(el)
+		delete(l.elems, key)
+	}
+}
+
+// purgeCache drops the cached responses of a record. A wildcard or regex from
+// purges every host it matches.
+func (a *App) purgeCache(from, path string) int {
+	pattern, err := utils.ParseHostPattern(from)
+	if err != nil || pattern == nil {
+		return a.Cache.Purge(from, path)
+	}
+	return a.Cache.PurgeMatching(func(host string) bool {
+		_, ok := pattern.Match(host)
+		return ok
+	}, path)
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"prx/internal/models"
+	"prx/internal/services"
+	"prx/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// l4HelloTimeout bounds how long a client may take to send its ClientHello.
+const l4HelloTimeout = 10 * time.Second
+
+// isL4 reports whether a record is proxied at layer 4.
+func isL4(protocol string) bool {
+	return protocol == models.ProtocolTLS || protocol == models.ProtocolTCP
+}
+
+// passthroughListener routes TLS connections whose server name belongs to a
+// tls record to its upstream without terminating them. Every other
+// connection is handed to the TLS server through Accept, or closed when prx
+// does not terminate TLS itself.
+type passthroughListener struct {
+	net.Listener
+	app       *App
+	terminate bool
+	conns     chan net.Conn
+	errc      chan error
+}
+
+func (a *App) newPassthroughListener(ln net.Listener, terminate bool) *passthroughListener {
+	l := &passthroughListener{
+		Listener:  ln,
+		app:       a,
+		terminate: terminate,
+		conns:     make(chan net.Conn),
+		errc:      make(chan error, 1),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+func (l *passthroughListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.errc <- err
+			return
+		}
+		go l.route(conn)
+	}
+}
+
+// Accept returns the connections prx terminates itself.
+func (l *passthroughListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errc:
+		l.errc <- err
+		return nil, err
+	}
+}
+
+// wait blocks until the listener fails, for passthrough without a TLS server.
+func (l *passthroughListener) wait() error {
+	err := <-l.errc
+	l.errc <- err
+	return err
+}
+
+func (l *passthroughListener) route(conn net.Conn) {
+	name, peeked, err := services.PeekServerName(conn, l4HelloTimeout)
+	if err == nil {
+		if record, ok := l.app.l4Record(name, models.ProtocolTLS); ok {
+			l.app.serveL4(peeked, record)
+			return
+		}
+	}
+
+	if !l.terminate {
+		l.app.Log.Warn("Closing tls connection without a passthrough record", "serverName", name, "client", conn.RemoteAddr(), "err", err)
+		conn.Close()
+		return
+	}
+	select {
+	case l.conns <- peeked:
+	case err := <-l.errc:
+		l.errc <- err
+		conn.Close()
+	}
+}
+
+// l4Record returns the record of a layer 4 connection with the placeholders
+// of a wildcard host filled in.
+func (a *App) l4Record(host, protocol string) (services.ProxyMapping, bool) {
+	record, labels, ok := a.readRedirectRecord(strings.ToLower(strings.TrimSuffix(host, ".")))
+	if !ok || record.Protocol != protocol {
+		return services.ProxyMapping{}, false
+	}
+	if hostLabelsUsed(record) {
+		record = expandRecord(record, labels)
+	}
+	return record, true
+}
+
+// listenTCP accepts the connections of a tcp record on its listen port until
+// the record is deleted.
+func (a *App) listenTCP(record services.ProxyMapping) {
+	addr := ":" + strconv.Itoa(record.ListenPort)
+	ln, err := a.listen(addr)
+	if err != nil {
+		a.Log.Error("Failed to listen for tcp record", "host", record.From, "addr", addr, "err", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.tcpListeners[record.From] = ln
+	a.mu.Unlock()
+	a.Log.Info("TCP listener started", "host", record.From, "addr", addr, "upstream", record.To)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				a.Log.Info("TCP listener stopped", "host", record.From, "addr", addr)
+				return
+			}
+			go func() {
+				current, ok := a.l4Record(record.From, models.ProtocolTCP)
+				if !ok {
+					conn.Close()
+					return
+				}
+				a.serveL4(conn, current)
+			}()
+		}
+	}()
+}
+
+// closeTCPListener stops accepting connections of a tcp record. Open
+// connections are kept. Callers must hold a.mu.
+func (a *App) closeTCPListener(host string) {
+	if ln, ok := a.tcpListeners[host]; ok {
+		ln.Close()
+		delete(a.tcpListeners, host)
+	}
+}
+
+// serveL4 checks the client against the IP access lists and splices its
+// connection to the upstream of the record.
+func (a *App) serveL4(client net.Conn, record services.ProxyMapping) {
+	ip, _, err := net.SplitHostPort(client.RemoteAddr().String())
+	if err != nil {
+		ip = client.RemoteAddr().String()
+	}
+
+	decision, rule := accessDecision(ip, "global", nil, a.globalDeny)
+	if access := record.IPAccess; access != nil && decision == "allow" {
+		allow, _ := utils.ParseCIDRs(access.Allow)
+		deny, _ := utils.ParseCIDRs(access.Deny)
+		decision, rule = accessDecision(ip, "record", allow, deny)
+	}
+	if decision == "deny" {
+		a.Log.Info("Rejected layer 4 connection", "host", record.From, "client", ip, "rule", rule)
+		client.Close()
+		return
+	}
+
+	dialTimeout, idle := a.upstreamSettings.DialTimeout, time.Duration(0)
+	if limits := record.Limits; limits != nil {
+		if d, ok := limitDuration(limits.ConnectTimeout); ok {
+			dialTimeout = d
+		}
+		if d, ok := limitDuration(limits.IdleTimeout); ok {
+			idle = d
+		}
+	}
+
+	upstream, err := net.DialTimeout("tcp", record.To, dialTimeout)
+	if err != nil {
+		a.Log.Error("Failed to connect layer 4 upstream", "host", record.From, "client", ip, "upstream", record.To, "err", err)
+		client.Close()
+		return
+	}
+	if record.ProxyProtocol != "" {
+		if err := services.WriteProxyHeader(upstream, record.ProxyProtocol, client.RemoteAddr(), client.LocalAddr()); err != nil {
+			a.Log.Error("Failed to send PROXY protocol header", "host", record.From, "upstream", record.To, "err", err)
+			client.Close()
+			upstream.Close()
+			return
+		}
+	}
+
+	start := time.Now()
+	sent, received := services.Splice(client, upstream, idle)
+	a.Log.Info("Layer 4 connection closed", "host", record.From, "protocol", record.Protocol, "client", ip,
+		"upstream", record.To, "sent", sent, "received", received, "duration", time.Since(start))
+}
+
+// recordError checks what a record needs beyond its spec: the uploaded
+// certificate of HTTP records and a free listen port for tcp records.
+func (a *App) recordError(from, cert, key string, spec models.ProxySpec) string {
+	if !isL4(spec.Protocol) {
+		return a.certificateError(cert, key)
+	}
+	if cert != "" || key != "" {
+		return "cert and key are not used by protocol " + spec.Protocol
+	}
+	if spec.Protocol != models.ProtocolTCP {
+		return ""
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for other, record := range a.RedirectRecords {
+		if other != from && record.Protocol == models.ProtocolTCP && record.ListenPort == spec.ListenPort {
+			return fmt.Sprintf("listenPort %d is used by %s", spec.ListenPort, other)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+type clientBodyKey struct{}
+
+// clientBody remembers why reading the client's request body failed, so an
+// upstream failure caused by the client is not answered as a 502.
+type clientBody struct {
+	io.ReadCloser
+	err error
+}
+
+func (b *clientBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// limitBody enforces the record's request body limit. Requests announcing a
+// larger body are answered with 413 right away; chunked bodies are cut off
+// once they pass the limit, which errorHandler answers with 413 as well.
+func (h *recordHandler) limitBody(w http.ResponseWriter, req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if h.maxBody > 0 && req.ContentLength > h.maxBody {
+		h.app.Log.Warn("Request body too large", "host", req.Host, "path", req.URL.Path, "length", req.ContentLength, "limit", h.maxBody)
+		h.app.respondError(w, req, h.record.ErrorPages, h.app.Err("request body exceeds %d bytes", h.maxBody), http.StatusRequestEntityTooLarge)
+		return req, false
+	}
+
+	body := req.Body
+	if h.maxBody > 0 {
+		body = http.MaxBytesReader(w, body, h.maxBody)
+	}
+	cb := &clientBody{ReadCloser: body}
+	req = req.WithContext(context.WithValue(req.Context(), clientBodyKey{}, cb))
+	req.Body = cb
+	return req, true
+}
+
+// upstreamErrorStatus maps the failure of an upstream request to the status
+// answered to the client: 413 or 408 when the client's body was too large
+// or too slow, 504 when the upstream timed out and 502 otherwise.
+func upstreamErrorStatus(req *http.Request, err error) (int, string) {
+	var netErr net.Error
+	if cb, ok := req.Context().Value(clientBodyKey{}).(*clientBody); ok && cb.err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(cb.err, &tooLarge):
+			return http.StatusRequestEntityTooLarge, "request body too large"
+		case errors.As(cb.err, &netErr) && netErr.Timeout():
+			return http.StatusRequestTimeout, "request body timed out"
+		}
+		return http.StatusBadRequest, "request body could not be read"
+	}
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout, "upstream request timed out"
+	}
+	return http.StatusBadGateway, "upstream request failed"
+}
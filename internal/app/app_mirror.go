@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"prx/internal/services"
+)
+
+// hopHeaders are not forwarded to a mirror, the same as the reverse proxy
+// drops them for the live request.
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// mirror sends a copy of a sampled request to the record's mirror target.
+// The body is buffered so the live request and the copy can both read it;
+// requests whose body exceeds the policy's limit and upgrade requests are
+// not mirrored.
+func (h *recordHandler) mirror(req *http.Request) {
+	policy := h.record.Mirror
+	m := h.app.Mirror
+	if !m.Sample(policy.Percent) {
+		return
+	}
+	if req.Header.Get("Upgrade") != "" {
+		m.Drop(h.record.From)
+		return
+	}
+
+	limit := policy.MaxBodyBytes
+	if limit <= 0 {
+		limit = services.DefaultMirrorMaxBodyBytes
+	}
+	body, ok, err := bufferBody(req, limit)
+	if err != nil || !ok {
+		m.Drop(h.record.From)
+		return
+	}
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	out := req.Clone(context.Background())
+	out.RequestURI = ""
+	out.Close = false
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	for _, name := range hopHeaders {
+		out.Header.Del(name)
+	}
+
+	pr := &httputil.ProxyRequest{In: req, Out: out}
+	pr.SetURL(h.mirrorTarget)
+	pr.SetXForwarded()
+	h.app.keepForwardedFor(req, out)
+	out.Host = req.Host
+	h.requestHeaders.applyRequest(out, headerVarsFrom(req.Context()))
+
+	m.Send(h.record.From, out)
+}
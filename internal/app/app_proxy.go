@@ -0,0 +1,383 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"prx/internal/models"
+	"prx/internal/services"
+	"prx/internal/utils"
+	"strconv"
+	"time"
+)
+
+type upstreamTargetKey struct{}
+
+// upstreamTarget is the upstream chosen for a single request together with
+// the bookkeeping that has to be settled once the request is done.
+type upstreamTarget struct {
+	in      *url.URL
+	url     *url.URL
+	backend *services.Backend
+	breaker *services.Breaker
+
+	// affinity is the upstream ID in the client's affinity cookie.
+	affinity string
+}
+
+func (t *upstreamTarget) release() {
+	if t.backend != nil {
+		t.backend.Release()
+	}
+}
+
+type compiledRoute struct {
+	rule   models.PathRule
+	target *url.URL
+}
+
+// recordHandler is the data plane of a single record. It is built once per
+// record version and cached by the App, so the parsed targets, the balancer
+// state and the reverse proxy are reused across requests. The upstream for a
+// request travels to the proxy hooks through the request context.
+type recordHandler struct {
+	app          *App
+	record       services.ProxyMapping
+	routes       []compiledRoute
+	balancer     *services.Balancer
+	transport    *http.Transport
+	timeout      time.Duration
+	maxBody      int64
+	breakers     *services.BreakerGroup
+	limit        *rateLimit
+	proxy        *httputil.ReverseProxy
+	redirect     *redirectTarget
+	mirrorTarget *url.URL
+	canary       *canaryRouter
+	allow        utils.CIDRList
+	deny         utils.CIDRList
+	cors         *corsPolicy
+
+	requestHeaders  *headerRules
+	responseHeaders *headerRules
+}
+
+func (a *App) newRecordHandler(record services.ProxyMapping) (*recordHandler, error) {
+	h := &recordHandler{
+		app:    a,
+		record: record,
+	}
+
+	if access := record.IPAccess; access != nil {
+		var err error
+		if h.allow, err = utils.ParseCIDRs(access.Allow); err != nil {
+			return nil, err
+		}
+		if h.deny, err = utils.ParseCIDRs(access.Deny); err != nil {
+			return nil, err
+		}
+	}
+
+	cors, err := newCORSPolicy(record.CORS)
+	if err != nil {
+		return nil, err
+	}
+	h.cors = cors
+
+	h.limit = newRateLimit(record.RateLimit)
+	h.requestHeaders = compileHeaderRules(record.RequestHeaders)
+	h.responseHeaders = compileHeaderRules(record.ResponseHeaders)
+
+	if record.Mode == models.ModeRedirect {
+		redirect, err := newRedirectTarget(record)
+		if err != nil {
+			return nil, err
+		}
+		h.redirect = redirect
+		return h, nil
+	}
+
+	for _, rule := range record.Routes {
+		target, err := url.Parse(rule.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route target %s: %v", rule.To, err)
+		}
+		h.routes = append(h.routes, compiledRoute{rule: rule, target: target})
+	}
+
+	if record.Mirror != nil {
+		target, err := url.Parse(record.Mirror.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mirror target %s: %v", record.Mirror.URL, err)
+		}
+		h.mirrorTarget = target
+	}
+
+	if record.Canary != nil {
+		canary, err := newCanaryRouter(*record.Canary)
+		if err != nil {
+			return nil, err
+		}
+		h.canary = canary
+	}
+
+	h.transport = a.upstreamTransport(record)
+	h.timeout, h.maxBody = a.server.RequestTimeout, a.server.MaxRequestBodyBytes
+	if limits := record.Limits; limits != nil {
+		if d, ok := limitDuration(limits.Timeout); ok {
+			h.timeout = d
+		}
+		if limits.MaxRequestBodyBytes > 0 {
+			h.maxBody = limits.MaxRequestBodyBytes
+		}
+	}
+
+	lb, err := services.NewBalancer(upstreamsOf(record), record.Policy)
+	if err != nil {
+		return nil, err
+	}
+	h.balancer = lb
+
+	if record.CircuitBreaker != nil {
+		h.breakers = services.NewBreakerGroup(*record.CircuitBreaker)
+	}
+
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite:        h.rewrite,
+		Transport:      h,
+		ModifyResponse: h.modifyResponse,
+		ErrorHandler:   h.errorHandler,
+	}
+	if isHTTP2(record.Protocol) {
+		// Streams are relayed as they arrive instead of in batches.
+		h.proxy.FlushInterval = -1
+	}
+
+	return h, nil
+}
+
+// recordHandler returns the cached handler of a record, building it on first
+// use. The cache entry is dropped whenever the record changes. labels are
+// the host labels matched by a wildcard or regex record; their handlers are
+// bounded by a.labeledHandlers.
+func (a *App) recordHandler(record services.ProxyMapping, labels []string) (*recordHandler, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := handlerKey(record, labels)
+	if h, ok := a.handlers[key]; ok {
+		if key != record.From {
+			a.labeledHandlers.touch(key)
+		}
+		return h, nil
+	}
+	h, err := a.newRecordHandler(expandRecord(record, labels))
+	if err != nil {
+		return nil, err
+	}
+	a.handlers[key] = h
+	if key != record.From {
+		for _, evicted := range a.labeledHandlers.touch(key) {
+			a.dropHandler(evicted)
+		}
+	}
+	return h, nil
+}
+
+func (h *recordHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h.record.IPAccess != nil && !allowIP(w, req, "record", h.allow, h.deny) {
+		return
+	}
+	if m := h.record.Maintenance; m != nil && m.Enabled {
+		h.serveMaintenance(w, req)
+		return
+	}
+	preflight := h.cors != nil && isPreflight(req)
+	if h.limit != nil && (preflight || !h.limitsSubject()) && !h.limit.allow(w, req, "") {
+		return
+	}
+	if preflight {
+		h.cors.servePreflight(w, req)
+		return
+	}
+	if h.record.Auth != nil && !h.authenticate(w, req) {
+		return
+	}
+	// The subject is only trusted once its token was verified.
+	if h.limitsSubject() && !h.limit.allow(w, req, req.Header.Get(authUserHeader)) {
+		return
+	}
+	req, ok := h.limitBody(w, req)
+	if !ok {
+		return
+	}
+	if h.requestHeaders != nil || h.responseHeaders != nil {
+		req = req.WithContext(context.WithValue(req.Context(), headerVarsKey{}, newHeaderVars(req)))
+	}
+	if h.record.ProxyProtocol != "" {
+		src, dst := h.app.proxyAddrs(req)
+		req = req.WithContext(services.WithProxyAddrs(req.Context(), src, dst))
+	}
+	if h.redirect != nil {
+		h.serveRedirect(w, req)
+		return
+	}
+	if h.mirrorTarget != nil {
+		h.mirror(req)
+	}
+	if h.record.Cache != nil {
+		h.serveCached(w, req)
+		return
+	}
+	h.serveUpstream(w, req)
+}
+
+// serveUpstream proxies a request to the upstream picked for it.
+func (h *recordHandler) serveUpstream(w http.ResponseWriter, req *http.Request) {
+	a := h.app
+
+	canary := h.canary != nil && h.canary.pick(w, req)
+	target, req, err := h.resolveTarget(req, canary)
+	var openErr *services.CircuitOpenError
+	if errors.As(err, &openErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(openErr.RetryAfterSeconds()))
+		a.respondError(w, req, h.record.ErrorPages, a.Err("upstreams for host %s are unavailable: %s", req.Host, err), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrNoHealthyUpstream) {
+		a.respondError(w, req, h.record.ErrorPages, a.Err("no healthy upstream for host %s", req.Host), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		a.respondError(w, req, h.record.ErrorPages, a.Err("no upstream available for host %s: %s", req.Host, err), http.StatusBadGateway)
+		return
+	}
+	defer target.release()
+	target.in = req.URL
+
+	a.Log.Debug("Proxying request", "host", req.Host, "path", req.URL.Path, "target", target.url)
+
+	ctx := context.WithValue(req.Context(), upstreamTargetKey{}, target)
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	h.proxy.ServeHTTP(w, req.WithContext(ctx))
+}
+
+// resolveTarget picks the upstream for a request. A matching path rule wins
+// over the record's upstreams; when the rule strips its prefix the returned
+// request carries the rewritten path. Requests picked for the canary go to
+// its target instead of the upstreams. Upstreams that failed their health
+// checks or whose circuit breaker is open are skipped.
+func (h *recordHandler) resolveTarget(req *http.Request, canary bool) (*upstreamTarget, *http.Request, error) {
+	if route, ok := h.matchRoute(req.URL.Path); ok {
+		if route.rule.StripPrefix {
+			req = stripPathPrefix(req, route.rule.Prefix)
+		}
+		t, err := h.fixedTarget(route.target)
+		return t, req, err
+	}
+	if canary {
+		t, err := h.fixedTarget(h.canary.target)
+		return t, req, err
+	}
+
+	backend, err := h.pickBackend(req, func(b *services.Backend) bool {
+		if h.breakers != nil && !h.breakers.Get(b.URL).Available() {
+			return false
+		}
+		return h.app.Health.Healthy(h.record.From, b.URL.String())
+	})
+	if errors.Is(err, services.ErrNoHealthyUpstream) && h.breakers != nil && h.breakers.RetryAfter() > 0 {
+		return nil, req, &services.CircuitOpenError{RetryAfter: h.breakers.RetryAfter()}
+	}
+	if err != nil {
+		return nil, req, err
+	}
+
+	t := &upstreamTarget{url: backend.URL, backend: backend, affinity: h.affinityCookie(req)}
+	if h.breakers != nil {
+		t.breaker = h.breakers.Get(backend.URL)
+		// Another request may have claimed the half-open probe in the meantime.
+		if !t.breaker.Allow() {
+			return nil, req, &services.CircuitOpenError{RetryAfter: t.breaker.RetryAfter()}
+		}
+	}
+	backend.Acquire()
+	return t, req, nil
+}
+
+// fixedTarget is the target of a path rule or canary, which is not balanced.
+func (h *recordHandler) fixedTarget(target *url.URL) (*upstreamTarget, error) {
+	t := &upstreamTarget{url: target}
+	if h.breakers != nil {
+		t.breaker = h.breakers.Get(target)
+		if !t.breaker.Allow() {
+			return nil, &services.CircuitOpenError{RetryAfter: t.breaker.RetryAfter()}
+		}
+	}
+	return t, nil
+}
+
+func (h *recordHandler) matchRoute(path string) (compiledRoute, bool) {
+	var best compiledRoute
+	found := false
+	for _, route := range h.routes {
+		if !pathHasPrefix(path, route.rule.Prefix) {
+			continue
+		}
+		if !found || len(route.rule.Prefix) > len(best.rule.Prefix) {
+			best = route
+			found = true
+		}
+	}
+	return best, found
+}
+
+// rewrite points the outbound request at the chosen upstream. The original
+// Host header is kept so virtual-hosted upstreams see the public hostname,
+// unless the record's request header rules override it.
+func (h *recordHandler) rewrite(pr *httputil.ProxyRequest) {
+	target := pr.In.Context().Value(upstreamTargetKey{}).(*upstreamTarget)
+	pr.SetURL(target.url)
+	pr.SetXForwarded()
+	h.app.keepForwardedFor(pr.In, pr.Out)
+	pr.Out.Host = pr.In.Host
+	h.requestHeaders.applyRequest(pr.Out, headerVarsFrom(pr.In.Context()))
+}
+
+// modifyResponse pins the client to its upstream and applies the record's
+// CORS policy and response header rules. When the cache is fetching the
+// response, it gets the upstream headers from before all of them so cache
+// hits can apply the rules afresh.
+func (h *recordHandler) modifyResponse(res *http.Response) error {
+	ctx := res.Request.Context()
+	if fetched, ok := ctx.Value(upstreamHeaderKey{}).(*upstreamHeader); ok {
+		fetched.header = res.Header.Clone()
+		fetched.trailer = len(res.Trailer) > 0
+	}
+	h.stickAffinity(res)
+	h.cors.apply(res.Request, res.Header)
+	h.responseHeaders.apply(res.Header, headerVarsFrom(ctx))
+	return nil
+}
+
+// errorHandler answers a request whose upstream failed with the status from
+// upstreamErrorStatus, using the record's error pages.
+func (h *recordHandler) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := upstreamErrorStatus(r, err)
+	if status < http.StatusInternalServerError {
+		h.app.Log.Warn("Request rejected", "host", r.Host, "path", r.URL.Path, "status", status, "reason", message, "err", err)
+	} else {
+		h.app.Log.Error("Upstream request failed", "host", r.Host, "status", status, "err", err)
+	}
+	// The error itself may name upstream addresses, so pages only get a summary.
+	if !h.app.serveErrorPage(w, r, h.record.ErrorPages, "", status, message) {
+		w.WriteHeader(status)
+	}
+}
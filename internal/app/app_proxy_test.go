@@ -0,0 +1,86 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"prx/internal/services"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func newBenchApp() *App {
+	logger := log.New(io.Discard)
+	settings := services.TransportSettingsFromEnv()
+	return &App{
+		Log:              logger,
+		Health:           services.NewHealthChecker(logger),
+		RedirectRecords:  make(map[string]services.ProxyMapping),
+		hosts:            &hostIndex{},
+		handlers:         make(map[string]*recordHandler),
+		labeledHandlers:  newHandlerLRU(defaultLabeledHandlers),
+		Transport:        services.NewTransport(settings),
+		upstreamSettings: settings,
+		Retries:          services.NewRetryBudgetFromEnv(),
+		Cache:            services.NewCacheFromEnv(logger),
+		Mirror:           services.NewMirrorFromEnv(logger, nil),
+	}
+}
+
+func newBenchUpstream(b *testing.B) *httptest.Server {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	b.Cleanup(upstream.Close)
+	return upstream
+}
+
+// benchProxy runs requests from more concurrent clients than the default
+// transport keeps idle connections for, as a busy record sees them.
+func benchProxy(b *testing.B, serve func(w http.ResponseWriter, req *http.Request)) {
+	b.ReportAllocs()
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			rec := httptest.NewRecorder()
+			serve(rec, req)
+			if rec.Code != http.StatusOK {
+				b.Errorf("status %d", rec.Code)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkSingleHostReverseProxy is the data plane before records got a
+// cached handler: a new proxy on the default transport for every request.
+func BenchmarkSingleHostReverseProxy(b *testing.B) {
+	upstream := newBenchUpstream(b)
+	target, _ := url.Parse(upstream.URL)
+
+	benchProxy(b, func(w http.ResponseWriter, req *http.Request) {
+		httputil.NewSingleHostReverseProxy(target).ServeHTTP(w, req)
+	})
+}
+
+// BenchmarkRecordHandler serves the same requests through the cached record
+// handler and the shared, pooled transport.
+func BenchmarkRecordHandler(b *testing.B) {
+	upstream := newBenchUpstream(b)
+	a := newBenchApp()
+	record := services.ProxyMapping{From: "example.com", To: upstream.URL}
+
+	benchProxy(b, func(w http.ResponseWriter, req *http.Request) {
+		h, err := a.recordHandler(record, nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"prx/internal/services"
+	"time"
+)
+
+// proxyHeaderTimeout bounds how long a trusted balancer may take to send the
+// PROXY protocol header of a connection.
+const proxyHeaderTimeout = 10 * time.Second
+
+// listen opens a listener for client traffic. With PRX_PROXY_PROTOCOL,
+// connections from PRX_TRUSTED_PROXIES must start with a PROXY protocol
+// header and carry the address of the client it names from then on.
+func (a *App) listen(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil || !a.proxyProtocol {
+		return ln, err
+	}
+	return services.NewProxyProtocolListener(a.Log, ln, a.trustedProxies.Contains, proxyHeaderTimeout), nil
+}
+
+// proxyAddrs returns the client of a request and the address it connected
+// to, as announced to upstreams of records sending the PROXY protocol. A
+// client taken from X-Forwarded-For is announced with port 0.
+func (a *App) proxyAddrs(req *http.Request) (src, dst net.Addr) {
+	dst, _ = req.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	ip, err := netip.ParseAddr(a.realClientIP(req))
+	if err != nil {
+		return nil, dst
+	}
+	var port uint16
+	if remote, err := netip.ParseAddrPort(req.RemoteAddr); err == nil && remote.Addr() == ip {
+		port = remote.Port()
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, port)), dst
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"math"
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"strconv"
+	"time"
+)
+
+// rateLimit is a limiter together with the rule that keys its clients.
+type rateLimit struct {
+	cfg     models.RateLimit
+	limiter *services.RateLimiter
+}
+
+func newRateLimit(cfg *models.RateLimit) *rateLimit {
+	if cfg == nil {
+		return nil
+	}
+	return &rateLimit{cfg: *cfg, limiter: services.NewRateLimiter(*cfg)}
+}
+
+// allow charges req against the limit. subject is the verified subject of
+// the request, if any. It writes the RateLimit-* headers and, when the
+// client is over the limit, a 429 answer.
+func (rl *rateLimit) allow(w http.ResponseWriter, req *http.Request, subject string) bool {
+	d := rl.limiter.Allow(rateLimitKey(rl.cfg, req, subject))
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+	h.Set("RateLimit-Policy", strconv.Itoa(d.Limit)+";w="+strconv.Itoa(ceilSeconds(d.Window)))
+	if d.Allowed {
+		return true
+	}
+
+	h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+	http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+	return false
+}
+
+// rateLimitKey identifies the client of a request according to the limit's
+// key, falling back to the client IP when the header or verified subject is
+// missing.
+func rateLimitKey(cfg models.RateLimit, req *http.Request, subject string) string {
+	switch cfg.Key {
+	case models.RateLimitKeyHeader:
+		if v := req.Header.Get(cfg.Header); v != "" {
+			return "header:" + v
+		}
+	case models.RateLimitKeyJWTSub:
+		if subject != "" {
+			return "sub:" + subject
+		}
+	}
+	return "ip:" + clientIP(req)
+}
+
+// limitsSubject reports whether the record's limit is keyed on the subject
+// verified by its JWT edge auth, which then has to run first.
+func (h *recordHandler) limitsSubject() bool {
+	return h.limit != nil && h.limit.cfg.Key == models.RateLimitKeyJWTSub &&
+		h.record.Auth != nil && h.record.Auth.Type == models.AuthJWT
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"prx/internal/models"
+	"prx/internal/services"
+	"regexp"
+	"strings"
+)
+
+type compiledRewrite struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// redirectTarget is the compiled form of a redirect record.
+type redirectTarget struct {
+	base     *url.URL
+	status   int
+	rule     models.RedirectRule
+	rewrites []compiledRewrite
+}
+
+func newRedirectTarget(record services.ProxyMapping) (*redirectTarget, error) {
+	base, err := url.Parse(record.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect target %s: %v", record.To, err)
+	}
+
+	t := &redirectTarget{base: base, status: http.StatusFound}
+	if record.Redirect == nil {
+		return t, nil
+	}
+	t.rule = *record.Redirect
+	if t.rule.Status != 0 {
+		t.status = t.rule.Status
+	}
+	for _, rw := range t.rule.Rewrites {
+		re, err := regexp.Compile(rw.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite %s: %v", rw.Match, err)
+		}
+		t.rewrites = append(t.rewrites, compiledRewrite{re: re, replacement: rw.Replacement})
+	}
+	return t, nil
+}
+
+// serveRedirect answers a request of a redirect record without contacting
+// any upstream.
+func (h *recordHandler) serveRedirect(w http.ResponseWriter, req *http.Request) {
+	location := h.redirect.location(req.URL)
+	h.cors.apply(req, w.Header())
+	h.responseHeaders.apply(w.Header(), headerVarsFrom(req.Context()))
+
+	h.app.Log.Debug("Redirecting request", "host", req.Host, "path", req.URL.Path, "location", location)
+	http.Redirect(w, req, location, h.redirect.status)
+}
+
+// location builds the redirect location for a request URL. The first
+// matching rewrite decides the path and may add a query; an absolute URL as
+// its result replaces the target altogether.
+func (t *redirectTarget) location(in *url.URL) string {
+	u := *t.base
+
+	var ref *url.URL
+	for _, rw := range t.rewrites {
+		if !rw.re.MatchString(in.Path) {
+			continue
+		}
+		ref, _ = url.Parse(rw.re.ReplaceAllString(in.Path, rw.replacement))
+		break
+	}
+
+	switch {
+	case ref != nil && ref.IsAbs():
+		u = *ref
+	case ref != nil:
+		u.Path = strings.TrimSuffix(u.Path, "/") + ensureLeadingSlash(ref.Path)
+		u.RawPath = ""
+		u.RawQuery = joinQuery(u.RawQuery, ref.RawQuery)
+	case t.rule.PreservePath:
+		u.Path = strings.TrimSuffix(u.Path, "/") + ensureLeadingSlash(in.Path)
+		u.RawPath = ""
+	}
+
+	if t.rule.PreserveQuery {
+		u.RawQuery = joinQuery(u.RawQuery, in.RawQuery)
+	}
+	return u.String()
+}
+
+func joinQuery(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "&" + b
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"prx/internal/models"
+	"prx/internal/services"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// RoundTrip sends the outbound request of the reverse proxy and reports every
+// attempt to the upstream's circuit breaker. When the record has a retry
+// policy, failed attempts are retried on another upstream within the global
+// retry budget. The request body is buffered up to the policy's limit so it
+// can be replayed; larger bodies are streamed and never retried.
+func (h *recordHandler) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := req.Context().Value(upstreamTargetKey{}).(*upstreamTarget)
+	policy := h.record.Retry
+
+	if policy == nil || policy.Attempts <= 1 || !retryableMethod(req, policy) {
+		res, err := h.transport.RoundTrip(req)
+		h.report(target, req, res, err)
+		return res, err
+	}
+	h.app.Retries.Deposit()
+
+	body, ok, err := bufferBody(req, policy.MaxBufferBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		res, err := h.transport.RoundTrip(req)
+		h.report(target, req, res, err)
+		return res, err
+	}
+
+	base, limit := services.RetryBackoffs(*policy)
+	tried := map[string]bool{}
+
+	out := req
+	for attempt := 1; ; attempt++ {
+		if body != nil {
+			out.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		res, err := h.transport.RoundTrip(out)
+		h.report(target, out, res, err)
+
+		if attempt >= policy.Attempts || req.Context().Err() != nil || !retryableResult(policy, res, err) {
+			return res, err
+		}
+		if !h.app.Retries.Withdraw() {
+			h.app.Log.Warn("Retry budget exhausted", "host", req.Host, "target", target.url)
+			return res, err
+		}
+
+		wait := time.NewTimer(services.Backoff(attempt, base, limit))
+		select {
+		case <-req.Context().Done():
+			wait.Stop()
+			closeBody(res)
+			return nil, req.Context().Err()
+		case <-wait.C:
+		}
+
+		tried[target.url.String()] = true
+		if !h.retarget(target, tried) {
+			return res, err
+		}
+		closeBody(res)
+
+		h.app.Log.Debug("Retrying upstream request", "host", req.Host, "attempt", attempt+1, "target", target.url)
+
+		out = req.Clone(req.Context())
+		u := *target.in
+		out.URL = &u
+		(&httputil.ProxyRequest{Out: out}).SetURL(target.url)
+		out.Host = req.Host
+	}
+}
+
+// retarget moves a request to the upstream of its next attempt. Path rules
+// only have one target, so they are retried in place; balanced records prefer
+// an upstream that has not been tried yet. It returns false when no upstream
+// may take the retry.
+func (h *recordHandler) retarget(t *upstreamTarget, tried map[string]bool) bool {
+	if t.backend == nil {
+		return t.breaker == nil || t.breaker.Allow()
+	}
+
+	available := func(b *services.Backend) bool {
+		if h.breakers != nil && !h.breakers.Get(b.URL).Available() {
+			return false
+		}
+		return h.app.Health.Healthy(h.record.From, b.URL.String())
+	}
+	backend, err := h.balancer.Pick("", func(b *services.Backend) bool {
+		return !tried[b.URL.String()] && available(b)
+	})
+	if err != nil {
+		backend, err = h.balancer.Pick("", available)
+	}
+	if err != nil {
+		return false
+	}
+
+	var breaker *services.Breaker
+	if h.breakers != nil {
+		breaker = h.breakers.Get(backend.URL)
+		if !breaker.Allow() {
+			return false
+		}
+	}
+
+	t.backend.Release()
+	backend.Acquire()
+	t.url, t.backend, t.breaker = backend.URL, backend, breaker
+	return true
+}
+
+// report feeds the outcome of one upstream attempt to its circuit breaker.
+func (h *recordHandler) report(t *upstreamTarget, req *http.Request, res *http.Response, err error) {
+	if t.breaker == nil {
+		return
+	}
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		t.breaker.Abandon()
+	case err != nil || res.StatusCode >= http.StatusInternalServerError:
+		t.breaker.Failure()
+	default:
+		t.breaker.Success()
+	}
+}
+
+// retryableMethod reports whether a request may be sent twice. Like
+// net/http, requests carrying an idempotency key count as idempotent.
+func retryableMethod(req *http.Request, policy *models.RetryPolicy) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != "" {
+		return true
+	}
+	return policy.RetryNonIdempotent
+}
+
+func retryableResult(policy *models.RetryPolicy, res *http.Response, err error) bool {
+	if err == nil {
+		return slices.Contains(policy.StatusCodes, res.StatusCode)
+	}
+
+	on := policy.RetryOn
+	if len(on) == 0 {
+		on = []string{services.RetryOnConnectFailure, services.RetryOnReset}
+	}
+	var opErr *net.OpError
+	if slices.Contains(on, services.RetryOnConnectFailure) && errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if slices.Contains(on, services.RetryOnReset) &&
+		(errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+		return true
+	}
+	return false
+}
+
+// bufferBody reads the request body into memory so it can be replayed. When
+// the body is larger than limit, the part already read is stitched back in
+// front of the rest and ok is false.
+func bufferBody(req *http.Request, limit int64) (body []byte, ok bool, err error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true, nil
+	}
+	if limit <= 0 {
+		limit = services.DefaultRetryMaxBufferBytes
+	}
+
+	body, err = io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		return nil, false, nil
+	}
+	return body, true, nil
+}
+
+func closeBody(res *http.Response) {
+	if res != nil {
+		io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+		res.Body.Close()
+	}
+}
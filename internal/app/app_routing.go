@@ -0,0 +1,78 @@
+package app
+
+import (
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"strings"
+)
+
+// upstreamsOf returns the record's upstreams, treating a record that only has
+// a default target as a single upstream.
+func upstreamsOf(record services.ProxyMapping) []models.Upstream {
+	if len(record.Upstreams) > 0 {
+		return record.Upstreams
+	}
+	if record.To == "" {
+		return nil
+	}
+	return []models.Upstream{{URL: record.To, Weight: 1}}
+}
+
+func upstreamHashKey(record services.ProxyMapping, req *http.Request) string {
+	switch record.Policy {
+	case models.PolicyIPHash:
+		return clientIP(req)
+	case models.PolicyHeaderHash:
+		return req.Header.Get(record.HashHeader)
+	}
+	return ""
+}
+
+// clientIP returns the real client address resolved by LoggingMiddleware,
+// or the peer address for requests that did not pass through it.
+func clientIP(req *http.Request) string {
+	if info := requestInfoFrom(req); info != nil {
+		return info.clientIP
+	}
+	return remoteIP(req)
+}
+
+func requestScheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// pathHasPrefix reports whether path starts with prefix on a segment
+// boundary, so "/api" matches "/api" and "/api/users" but not "/apiary".
+func pathHasPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+// stripPathPrefix returns a shallow copy of req with prefix removed from the
+// URL path, the same way http.StripPrefix does for handlers.
+func stripPathPrefix(req *http.Request, prefix string) *http.Request {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	r2 := new(http.Request)
+	*r2 = *req
+	u := *req.URL
+	r2.URL = &u
+	r2.URL.Path = ensureLeadingSlash(strings.TrimPrefix(req.URL.Path, prefix))
+	if req.URL.RawPath != "" {
+		r2.URL.RawPath = ensureLeadingSlash(strings.TrimPrefix(req.URL.RawPath, prefix))
+	}
+	return r2
+}
+
+func ensureLeadingSlash(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		return "/" + p
+	}
+	return p
+}
@@ -7,6 +7,8 @@ import (
 
 	"prx/internal/models"
 	"prx/internal/pb"
+	"prx/internal/services"
+	"prx/internal/utils"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -85,13 +87,24 @@ func (s *grpcServer) Add(ctx context.Context, req *pb.ProxyRequest) (*pb.Empty,
 
 	s.app.Log.Info("RPC add new request", "req", req)
 
+	spec := specFromPb(req)
+	if errMsg := s.app.recordError(req.From, req.Cert, req.Key, spec); errMsg != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: %s", errMsg)
+	}
+	if errMsg := utils.ValidateProxySpec(req.From, req.To, spec); errMsg != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: %s", errMsg)
+	}
+
+	s.app.writes.Lock()
+	defer s.app.writes.Unlock()
+
 	err := s.app.Kube.AddNewProxy(models.AddNewProxy{
-		From: req.From, To: req.To, Cert: req.Cert, Key: req.Key,
+		From: req.From, To: req.To, Cert: req.Cert, Key: req.Key, ProxySpec: spec,
 	}, s.app.namespace, s.app.name)
 	if err != nil {
 		return nil, err
 	}
-	s.app.setRedirectRecords(req.From, req.To)
+	s.app.setRedirectRecords(services.ProxyMapping{From: req.From, To: req.To, ProxySpec: spec})
 	return &pb.Empty{}, nil
 }
 
@@ -99,16 +112,27 @@ func (s *grpcServer) Update(ctx context.Context, req *pb.ProxyRequest) (*pb.Empt
 
 	s.app.Log.Info("RPC update request", "req", req)
 
+	spec := specFromPb(req)
+	if errMsg := s.app.recordError(req.From, req.Cert, req.Key, spec); errMsg != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: %s", errMsg)
+	}
+	if errMsg := utils.ValidateProxySpec(req.From, req.To, spec); errMsg != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: %s", errMsg)
+	}
+
+	s.app.writes.Lock()
+	defer s.app.writes.Unlock()
+
 	if err := s.app.Kube.DeleteProxy(s.app.namespace, req.From); err != nil {
 		return nil, err
 	}
 	s.app.deleteRedirectRecords(req.From)
 	if err := s.app.Kube.AddNewProxy(models.AddNewProxy{
-		From: req.From, To: req.To, Cert: req.Cert, Key: req.Key,
+		From: req.From, To: req.To, Cert: req.Cert, Key: req.Key, ProxySpec: spec,
 	}, s.app.namespace, s.app.name); err != nil {
 		return nil, err
 	}
-	s.app.setRedirectRecords(req.From, req.To)
+	s.app.setRedirectRecords(services.ProxyMapping{From: req.From, To: req.To, ProxySpec: spec})
 	return &pb.Empty{}, nil
 }
 
@@ -116,6 +140,9 @@ func (s *grpcServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.Emp
 
 	s.app.Log.Info("RPC delete request", "req", req)
 
+	s.app.writes.Lock()
+	defer s.app.writes.Unlock()
+
 	if err := s.app.Kube.DeleteProxy(s.app.namespace, req.From); err != nil {
 		return nil, err
 	}
@@ -129,8 +156,557 @@ func (s *grpcServer) List(ctx context.Context, _ *pb.ListRequest) (*pb.ListRespo
 
 	records, _ := s.app.getAllRedirectionRecords()
 	resp := &pb.ListResponse{}
-	for from, to := range records {
-		resp.Records = append(resp.Records, &pb.ProxyRecord{From: from, To: to})
+	for from, record := range records {
+		rec := recordToPb(from, record)
+		rec.Health = upstreamHealthToPb(s.app.Health.Status(from))
+		rec.Breakers = breakerStatusToPb(s.app.breakerStatus(from))
+		rec.Mirrored = mirrorStatusToPb(s.app.mirrorStatus(record))
+		rec.Certificate = certificateStatusToPb(s.app.certificateStatus(from))
+		resp.Records = append(resp.Records, rec)
 	}
 	return resp, nil
 }
+
+func (s *grpcServer) PurgeCache(ctx context.Context, req *pb.PurgeCacheRequest) (*pb.PurgeCacheResponse, error) {
+
+	s.app.Log.Info("RPC purge cache request", "req", req)
+
+	if req.From == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: from is required")
+	}
+	purged := s.app.purgeCache(req.From, req.Path)
+	return &pb.PurgeCacheResponse{Purged: int32(purged)}, nil
+}
+
+func (s *grpcServer) Canary(ctx context.Context, req *pb.CanaryRequest) (*pb.Empty, error) {
+
+	s.app.Log.Info("RPC canary request", "req", req)
+
+	if req.From == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: from is required")
+	}
+	if req.Action != models.CanaryPromote && req.Action != models.CanaryAbort {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: unknown canary action %s", req.Action)
+	}
+	if err := s.app.changeCanary(req.From, req.Action); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "canary error: %v", err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) Maintenance(ctx context.Context, req *pb.MaintenanceRequest) (*pb.Empty, error) {
+
+	s.app.Log.Info("RPC maintenance request", "req", req)
+
+	if req.From == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: from is required")
+	}
+	if errMsg := utils.ValidateMaintenance(&models.Maintenance{RetryAfter: req.RetryAfter}); errMsg != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "validation error: %s", errMsg)
+	}
+	action := models.MaintenanceAction{From: req.From, Enabled: req.Enabled, RetryAfter: req.RetryAfter, Message: req.Message}
+	if err := s.app.changeMaintenance(action); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "maintenance error: %v", err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func specFromPb(req *pb.ProxyRequest) models.ProxySpec {
+	return models.ProxySpec{
+		Mode:            req.Mode,
+		Protocol:        req.Protocol,
+		ListenPort:      int(req.ListenPort),
+		ProxyProtocol:   req.ProxyProtocol,
+		Redirect:        redirectRuleFromPb(req.Redirect),
+		Routes:          pathRulesFromPb(req.Routes),
+		Upstreams:       upstreamsFromPb(req.Upstreams),
+		Policy:          req.Policy,
+		HashHeader:      req.HashHeader,
+		Affinity:        affinityFromPb(req.Affinity),
+		UpstreamTLS:     upstreamTLSFromPb(req.UpstreamTls),
+		Auth:            edgeAuthFromPb(req.Auth),
+		IPAccess:        ipAccessFromPb(req.IpAccess),
+		CORS:            corsFromPb(req.Cors),
+		Maintenance:     maintenanceFromPb(req.Maintenance),
+		ErrorPages:      req.ErrorPages,
+		HealthCheck:     healthCheckFromPb(req.HealthCheck),
+		CircuitBreaker:  circuitBreakerFromPb(req.CircuitBreaker),
+		Retry:           retryPolicyFromPb(req.Retry),
+		Limits:          limitsFromPb(req.Limits),
+		Cache:           cachePolicyFromPb(req.Cache),
+		RateLimit:       rateLimitFromPb(req.RateLimit),
+		RequestHeaders:  headerRulesFromPb(req.RequestHeaders),
+		ResponseHeaders: headerRulesFromPb(req.ResponseHeaders),
+		Mirror:          mirrorPolicyFromPb(req.Mirror),
+		Canary:          canaryFromPb(req.Canary),
+	}
+}
+
+func recordToPb(from string, record services.ProxyMapping) *pb.ProxyRecord {
+	return &pb.ProxyRecord{
+		From:            from,
+		To:              record.To,
+		Mode:            record.Mode,
+		Protocol:        record.Protocol,
+		ListenPort:      int32(record.ListenPort),
+		ProxyProtocol:   record.ProxyProtocol,
+		Redirect:        redirectRuleToPb(record.Redirect),
+		Routes:          pathRulesToPb(record.Routes),
+		Upstreams:       upstreamsToPb(record.Upstreams),
+		Policy:          record.Policy,
+		HashHeader:      record.HashHeader,
+		Affinity:        affinityToPb(record.Affinity),
+		UpstreamTls:     upstreamTLSToPb(record.UpstreamTLS),
+		Auth:            edgeAuthToPb(record.Auth),
+		IpAccess:        ipAccessToPb(record.IPAccess),
+		Cors:            corsToPb(record.CORS),
+		Maintenance:     maintenanceToPb(record.Maintenance),
+		ErrorPages:      record.ErrorPages,
+		HealthCheck:     healthCheckToPb(record.HealthCheck),
+		CircuitBreaker:  circuitBreakerToPb(record.CircuitBreaker),
+		Retry:           retryPolicyToPb(record.Retry),
+		Limits:          limitsToPb(record.Limits),
+		Cache:           cachePolicyToPb(record.Cache),
+		RateLimit:       rateLimitToPb(record.RateLimit),
+		RequestHeaders:  headerRulesToPb(record.RequestHeaders),
+		ResponseHeaders: headerRulesToPb(record.ResponseHeaders),
+		Mirror:          mirrorPolicyToPb(record.Mirror),
+		Canary:          canaryToPb(record.Canary),
+	}
+}
+
+func pathRulesFromPb(rules []*pb.PathRule) []models.PathRule {
+	var res []models.PathRule
+	for _, r := range rules {
+		res = append(res, models.PathRule{Prefix: r.Prefix, To: r.To, StripPrefix: r.StripPrefix})
+	}
+	return res
+}
+
+func pathRulesToPb(rules []models.PathRule) []*pb.PathRule {
+	var res []*pb.PathRule
+	for _, r := range rules {
+		res = append(res, &pb.PathRule{Prefix: r.Prefix, To: r.To, StripPrefix: r.StripPrefix})
+	}
+	return res
+}
+
+func upstreamsFromPb(upstreams []*pb.Upstream) []models.Upstream {
+	var res []models.Upstream
+	for _, u := range upstreams {
+		res = append(res, models.Upstream{URL: u.Url, Weight: int(u.Weight)})
+	}
+	return res
+}
+
+func upstreamsToPb(upstreams []models.Upstream) []*pb.Upstream {
+	var res []*pb.Upstream
+	for _, u := range upstreams {
+		res = append(res, &pb.Upstream{Url: u.URL, Weight: int32(u.Weight)})
+	}
+	return res
+}
+
+func healthCheckFromPb(hc *pb.HealthCheck) *models.HealthCheck {
+	if hc == nil {
+		return nil
+	}
+	return &models.HealthCheck{
+		Path:               hc.Path,
+		Interval:           hc.Interval,
+		Timeout:            hc.Timeout,
+		HealthyThreshold:   int(hc.HealthyThreshold),
+		UnhealthyThreshold: int(hc.UnhealthyThreshold),
+	}
+}
+
+func healthCheckToPb(hc *models.HealthCheck) *pb.HealthCheck {
+	if hc == nil {
+		return nil
+	}
+	return &pb.HealthCheck{
+		Path:               hc.Path,
+		Interval:           hc.Interval,
+		Timeout:            hc.Timeout,
+		HealthyThreshold:   int32(hc.HealthyThreshold),
+		UnhealthyThreshold: int32(hc.UnhealthyThreshold),
+	}
+}
+
+func upstreamHealthToPb(status []models.UpstreamHealth) []*pb.UpstreamHealth {
+	var res []*pb.UpstreamHealth
+	for _, h := range status {
+		res = append(res, &pb.UpstreamHealth{
+			Url:         h.URL,
+			State:       h.State,
+			LastError:   h.LastError,
+			LastChecked: h.LastChecked,
+		})
+	}
+	return res
+}
+
+func circuitBreakerFromPb(cb *pb.CircuitBreaker) *models.CircuitBreaker {
+	if cb == nil {
+		return nil
+	}
+	return &models.CircuitBreaker{
+		ConsecutiveFailures: int(cb.ConsecutiveFailures),
+		Cooldown:            cb.Cooldown,
+		SuccessThreshold:    int(cb.SuccessThreshold),
+	}
+}
+
+func circuitBreakerToPb(cb *models.CircuitBreaker) *pb.CircuitBreaker {
+	if cb == nil {
+		return nil
+	}
+	return &pb.CircuitBreaker{
+		ConsecutiveFailures: int32(cb.ConsecutiveFailures),
+		Cooldown:            cb.Cooldown,
+		SuccessThreshold:    int32(cb.SuccessThreshold),
+	}
+}
+
+func retryPolicyFromPb(r *pb.RetryPolicy) *models.RetryPolicy {
+	if r == nil {
+		return nil
+	}
+	var codes []int
+	for _, c := range r.StatusCodes {
+		codes = append(codes, int(c))
+	}
+	return &models.RetryPolicy{
+		Attempts:           int(r.Attempts),
+		RetryOn:            r.RetryOn,
+		StatusCodes:        codes,
+		BaseBackoff:        r.BaseBackoff,
+		MaxBackoff:         r.MaxBackoff,
+		RetryNonIdempotent: r.RetryNonIdempotent,
+		MaxBufferBytes:     r.MaxBufferBytes,
+	}
+}
+
+func retryPolicyToPb(r *models.RetryPolicy) *pb.RetryPolicy {
+	if r == nil {
+		return nil
+	}
+	var codes []int32
+	for _, c := range r.StatusCodes {
+		codes = append(codes, int32(c))
+	}
+	return &pb.RetryPolicy{
+		Attempts:           int32(r.Attempts),
+		RetryOn:            r.RetryOn,
+		StatusCodes:        codes,
+		BaseBackoff:        r.BaseBackoff,
+		MaxBackoff:         r.MaxBackoff,
+		RetryNonIdempotent: r.RetryNonIdempotent,
+		MaxBufferBytes:     r.MaxBufferBytes,
+	}
+}
+
+func cachePolicyFromPb(c *pb.CachePolicy) *models.CachePolicy {
+	if c == nil {
+		return nil
+	}
+	return &models.CachePolicy{DefaultTTL: c.DefaultTtl, MaxObjectBytes: c.MaxObjectBytes}
+}
+
+func cachePolicyToPb(c *models.CachePolicy) *pb.CachePolicy {
+	if c == nil {
+		return nil
+	}
+	return &pb.CachePolicy{DefaultTtl: c.DefaultTTL, MaxObjectBytes: c.MaxObjectBytes}
+}
+
+func rateLimitFromPb(rl *pb.RateLimit) *models.RateLimit {
+	if rl == nil {
+		return nil
+	}
+	return &models.RateLimit{
+		Requests: int(rl.Requests),
+		Period:   rl.Period,
+		Burst:    int(rl.Burst),
+		Key:      rl.Key,
+		Header:   rl.Header,
+	}
+}
+
+func rateLimitToPb(rl *models.RateLimit) *pb.RateLimit {
+	if rl == nil {
+		return nil
+	}
+	return &pb.RateLimit{
+		Requests: int32(rl.Requests),
+		Period:   rl.Period,
+		Burst:    int32(rl.Burst),
+		Key:      rl.Key,
+		Header:   rl.Header,
+	}
+}
+
+func headerRulesFromPb(r *pb.HeaderRules) *models.HeaderRules {
+	if r == nil {
+		return nil
+	}
+	return &models.HeaderRules{Set: r.Set, Add: r.Add, Remove: r.Remove}
+}
+
+func headerRulesToPb(r *models.HeaderRules) *pb.HeaderRules {
+	if r == nil {
+		return nil
+	}
+	return &pb.HeaderRules{Set: r.Set, Add: r.Add, Remove: r.Remove}
+}
+
+func redirectRuleFromPb(r *pb.RedirectRule) *models.RedirectRule {
+	if r == nil {
+		return nil
+	}
+	rule := &models.RedirectRule{
+		Status:        int(r.Status),
+		PreservePath:  r.PreservePath,
+		PreserveQuery: r.PreserveQuery,
+	}
+	for _, rw := range r.Rewrites {
+		rule.Rewrites = append(rule.Rewrites, models.RegexRewrite{Match: rw.Match, Replacement: rw.Replacement})
+	}
+	return rule
+}
+
+func redirectRuleToPb(r *models.RedirectRule) *pb.RedirectRule {
+	if r == nil {
+		return nil
+	}
+	rule := &pb.RedirectRule{
+		Status:        int32(r.Status),
+		PreservePath:  r.PreservePath,
+		PreserveQuery: r.PreserveQuery,
+	}
+	for _, rw := range r.Rewrites {
+		rule.Rewrites = append(rule.Rewrites, &pb.RegexRewrite{Match: rw.Match, Replacement: rw.Replacement})
+	}
+	return rule
+}
+
+func breakerStatusToPb(status []models.BreakerStatus) []*pb.BreakerStatus {
+	var res []*pb.BreakerStatus
+	for _, b := range status {
+		res = append(res, &pb.BreakerStatus{
+			Url:      b.URL,
+			State:    b.State,
+			Failures: int32(b.Failures),
+			OpenedAt: b.OpenedAt,
+		})
+	}
+	return res
+}
+
+func mirrorPolicyFromPb(m *pb.MirrorPolicy) *models.MirrorPolicy {
+	if m == nil {
+		return nil
+	}
+	return &models.MirrorPolicy{URL: m.Url, Percent: m.Percent, MaxBodyBytes: m.MaxBodyBytes}
+}
+
+func mirrorPolicyToPb(m *models.MirrorPolicy) *pb.MirrorPolicy {
+	if m == nil {
+		return nil
+	}
+	return &pb.MirrorPolicy{Url: m.URL, Percent: m.Percent, MaxBodyBytes: m.MaxBodyBytes}
+}
+
+func mirrorStatusToPb(m *models.MirrorStatus) *pb.MirrorStatus {
+	if m == nil {
+		return nil
+	}
+	return &pb.MirrorStatus{Succeeded: m.Succeeded, Failed: m.Failed, Dropped: m.Dropped}
+}
+
+func certificateStatusToPb(c *models.CertificateStatus) *pb.CertificateStatus {
+	if c == nil {
+		return nil
+	}
+	return &pb.CertificateStatus{State: c.State, NotAfter: c.NotAfter, LastError: c.LastError, NextAttempt: c.NextAttempt}
+}
+
+func canaryFromPb(c *pb.Canary) *models.Canary {
+	if c == nil {
+		return nil
+	}
+	canary := &models.Canary{To: c.To, Weight: int(c.Weight), StickyCookie: c.StickyCookie}
+	for _, m := range c.Matches {
+		canary.Matches = append(canary.Matches, models.CanaryMatch{Header: m.Header, Cookie: m.Cookie, Query: m.Query, Value: m.Value})
+	}
+	return canary
+}
+
+func canaryToPb(c *models.Canary) *pb.Canary {
+	if c == nil {
+		return nil
+	}
+	canary := &pb.Canary{To: c.To, Weight: int32(c.Weight), StickyCookie: c.StickyCookie}
+	for _, m := range c.Matches {
+		canary.Matches = append(canary.Matches, &pb.CanaryMatch{Header: m.Header, Cookie: m.Cookie, Query: m.Query, Value: m.Value})
+	}
+	return canary
+}
+
+func affinityFromPb(a *pb.SessionAffinity) *models.SessionAffinity {
+	if a == nil {
+		return nil
+	}
+	return &models.SessionAffinity{Type: a.Type, Cookie: a.Cookie, CookieTTL: a.CookieTtl, Header: a.Header}
+}
+
+func affinityToPb(a *models.SessionAffinity) *pb.SessionAffinity {
+	if a == nil {
+		return nil
+	}
+	return &pb.SessionAffinity{Type: a.Type, Cookie: a.Cookie, CookieTtl: a.CookieTTL, Header: a.Header}
+}
+
+func edgeAuthFromPb(a *pb.EdgeAuth) *models.EdgeAuth {
+	if a == nil {
+		return nil
+	}
+	return &models.EdgeAuth{
+		Type:         a.Type,
+		Realm:        a.Realm,
+		Secret:       a.Secret,
+		JWKSURL:      a.JwksUrl,
+		Issuer:       a.Issuer,
+		Audience:     a.Audience,
+		Claims:       a.Claims,
+		ClaimHeaders: a.ClaimHeaders,
+		URL:          a.Url,
+		AuthHeaders:  a.AuthHeaders,
+	}
+}
+
+func edgeAuthToPb(a *models.EdgeAuth) *pb.EdgeAuth {
+	if a == nil {
+		return nil
+	}
+	return &pb.EdgeAuth{
+		Type:         a.Type,
+		Realm:        a.Realm,
+		Secret:       a.Secret,
+		JwksUrl:      a.JWKSURL,
+		Issuer:       a.Issuer,
+		Audience:     a.Audience,
+		Claims:       a.Claims,
+		ClaimHeaders: a.ClaimHeaders,
+		Url:          a.URL,
+		AuthHeaders:  a.AuthHeaders,
+	}
+}
+
+func ipAccessFromPb(a *pb.IPAccess) *models.IPAccess {
+	if a == nil {
+		return nil
+	}
+	return &models.IPAccess{Allow: a.Allow, Deny: a.Deny}
+}
+
+func ipAccessToPb(a *models.IPAccess) *pb.IPAccess {
+	if a == nil {
+		return nil
+	}
+	return &pb.IPAccess{Allow: a.Allow, Deny: a.Deny}
+}
+
+func corsFromPb(c *pb.CORS) *models.CORS {
+	if c == nil {
+		return nil
+	}
+	return &models.CORS{
+		AllowOrigins:     c.AllowOrigins,
+		AllowMethods:     c.AllowMethods,
+		AllowHeaders:     c.AllowHeaders,
+		ExposeHeaders:    c.ExposeHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           int(c.MaxAge),
+	}
+}
+
+func corsToPb(c *models.CORS) *pb.CORS {
+	if c == nil {
+		return nil
+	}
+	return &pb.CORS{
+		AllowOrigins:     c.AllowOrigins,
+		AllowMethods:     c.AllowMethods,
+		AllowHeaders:     c.AllowHeaders,
+		ExposeHeaders:    c.ExposeHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           int32(c.MaxAge),
+	}
+}
+
+func maintenanceFromPb(m *pb.Maintenance) *models.Maintenance {
+	if m == nil {
+		return nil
+	}
+	return &models.Maintenance{Enabled: m.Enabled, RetryAfter: m.RetryAfter, Message: m.Message}
+}
+
+func maintenanceToPb(m *models.Maintenance) *pb.Maintenance {
+	if m == nil {
+		return nil
+	}
+	return &pb.Maintenance{Enabled: m.Enabled, RetryAfter: m.RetryAfter, Message: m.Message}
+}
+
+func upstreamTLSFromPb(t *pb.UpstreamTLS) *models.UpstreamTLS {
+	if t == nil {
+		return nil
+	}
+	return &models.UpstreamTLS{
+		CASecret:           t.CaSecret,
+		CertSecret:         t.CertSecret,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+}
+
+func upstreamTLSToPb(t *models.UpstreamTLS) *pb.UpstreamTLS {
+	if t == nil {
+		return nil
+	}
+	return &pb.UpstreamTLS{
+		CaSecret:           t.CASecret,
+		CertSecret:         t.CertSecret,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+}
+
+func limitsFromPb(l *pb.Limits) *models.Limits {
+	if l == nil {
+		return nil
+	}
+	return &models.Limits{
+		ConnectTimeout:         l.ConnectTimeout,
+		ResponseHeaderTimeout:  l.ResponseHeaderTimeout,
+		Timeout:                l.Timeout,
+		IdleTimeout:            l.IdleTimeout,
+		MaxRequestBodyBytes:    l.MaxRequestBodyBytes,
+		MaxResponseHeaderBytes: l.MaxResponseHeaderBytes,
+	}
+}
+
+func limitsToPb(l *models.Limits) *pb.Limits {
+	if l == nil {
+		return nil
+	}
+	return &pb.Limits{
+		ConnectTimeout:         l.ConnectTimeout,
+		ResponseHeaderTimeout:  l.ResponseHeaderTimeout,
+		Timeout:                l.Timeout,
+		IdleTimeout:            l.IdleTimeout,
+		MaxRequestBodyBytes:    l.MaxRequestBodyBytes,
+		MaxResponseHeaderBytes: l.MaxResponseHeaderBytes,
+	}
+}
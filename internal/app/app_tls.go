@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+)
+
+// newTLSServer builds the optional listener that terminates TLS itself, so
+// prx can run as the edge without an ingress controller. Certificates are
+// picked per SNI from the TLS Secrets prx manages.
+func (a *App) newTLSServer(addr string, plain *http.Server) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           plain.Handler,
+		Protocols:         protocols,
+		ReadHeaderTimeout: plain.ReadHeaderTimeout,
+		ReadTimeout:       plain.ReadTimeout,
+		WriteTimeout:      plain.WriteTimeout,
+		IdleTimeout:       plain.IdleTimeout,
+		MaxHeaderBytes:    plain.MaxHeaderBytes,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: a.Certs.GetCertificate,
+		},
+	}
+}
+
+// startTLS listens on the TLS address. With passthrough, connections for tls
+// records are spliced to their upstream before the TLS server sees them.
+func (a *App) startTLS() {
+	ln, err := a.listen(a.tlsAddr)
+	if err != nil {
+		a.Log.Fatal("TLS server failed to start:", "error", err)
+	}
+
+	if a.passthrough {
+		passthrough := a.newPassthroughListener(ln, a.TLS != nil)
+		if a.TLS == nil {
+			a.Log.Info("TLS passthrough started", "addr", a.tlsAddr)
+			a.Log.Fatal("TLS passthrough failed:", "error", passthrough.wait())
+		}
+		ln = passthrough
+	}
+
+	if err := a.Certs.Refresh(); err != nil {
+		a.Log.Error("Failed to load tls certificates, retrying in the background", "err", err)
+	}
+	go a.Certs.Run(context.Background())
+
+	a.Log.Info("TLS server started", "addr", a.tlsAddr, "passthrough", a.passthrough)
+	if err := a.TLS.ServeTLS(ln, "", ""); err != nil {
+		a.Log.Fatal("TLS server failed to start:", "error", err)
+	}
+}
+
+// refreshCerts picks up the TLS Secret of a record that was just added,
+// changed or deleted without waiting for the next refresh.
+func (a *App) refreshCerts() {
+	if a.Certs == nil {
+		return
+	}
+	go func() {
+		if err := a.Certs.Refresh(); err != nil {
+			a.Log.Error("Failed to refresh tls certificates", "err", err)
+		}
+	}()
+}
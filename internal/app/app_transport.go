@@ -0,0 +1,80 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"prx/internal/models"
+	"prx/internal/services"
+	"time"
+)
+
+// upstreamTransport returns the transport for the upstreams of a record. It
+// is the shared pool unless the record speaks HTTP/2, has upstream TLS
+// settings or its own connection limits, which get a copy of the pool.
+func (a *App) upstreamTransport(record services.ProxyMapping) *http.Transport {
+	tlsCfg, limits := record.UpstreamTLS, record.Limits
+	if !isHTTP2(record.Protocol) && tlsCfg == nil && record.ProxyProtocol == "" && (limits == nil || (limits.ConnectTimeout == "" &&
+		limits.ResponseHeaderTimeout == "" && limits.IdleTimeout == "" && limits.MaxResponseHeaderBytes == 0)) {
+		return a.Transport
+	}
+
+	t := a.Transport.Clone()
+	if isHTTP2(record.Protocol) {
+		// Without HTTP/1 the transport uses h2c for http:// upstreams and
+		// only offers h2 to https:// ones.
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP2(true)
+		t.Protocols.SetUnencryptedHTTP2(true)
+	}
+	if tlsCfg != nil {
+		if tlsCfg.InsecureSkipVerify {
+			a.Log.Warn("!!! UPSTREAM TLS VERIFICATION IS DISABLED: certificates of this record's upstreams are not checked !!!",
+				"host", record.From)
+		}
+		t.TLSClientConfig = a.UpstreamTLS.Config(*tlsCfg, "")
+	}
+	if limits != nil {
+		if d, ok := limitDuration(limits.ConnectTimeout); ok {
+			dialer := &net.Dialer{Timeout: d, KeepAlive: a.upstreamSettings.KeepAlive}
+			t.DialContext = dialer.DialContext
+		}
+		if d, ok := limitDuration(limits.ResponseHeaderTimeout); ok {
+			t.ResponseHeaderTimeout = d
+		}
+		if d, ok := limitDuration(limits.IdleTimeout); ok {
+			t.IdleConnTimeout = d
+		}
+		if limits.MaxResponseHeaderBytes > 0 {
+			t.MaxResponseHeaderBytes = limits.MaxResponseHeaderBytes
+		}
+	}
+	if record.ProxyProtocol != "" {
+		// The header names one client, so connections are not reused.
+		t.DialContext = services.ProxyProtocolDialer(record.ProxyProtocol, t.DialContext)
+		t.DisableKeepAlives = true
+	}
+	if tlsCfg != nil && tlsCfg.CASecret != "" && !tlsCfg.InsecureSkipVerify {
+		nextProtos := []string{"http/1.1"}
+		if isHTTP2(record.Protocol) {
+			nextProtos = []string{"h2"}
+		} else if t.ForceAttemptHTTP2 {
+			nextProtos = []string{"h2", "http/1.1"}
+		}
+		t.DialTLSContext = a.UpstreamTLS.DialTLS(*tlsCfg, t.DialContext, nextProtos, t.TLSHandshakeTimeout)
+	}
+	return t
+}
+
+func isHTTP2(protocol string) bool {
+	return protocol == models.ProtocolHTTP2 || protocol == models.ProtocolGRPC
+}
+
+// limitDuration parses a duration of models.Limits. ok is false for an empty
+// value, which keeps the default; "0s" turns the limit off.
+func limitDuration(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil
+}
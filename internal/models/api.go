@@ -5,7 +5,6 @@ type NewProxySettings struct {
 	Namespace string
 	Version   string
 	Secret    string
-	Records   map[string]string
 }
 type Response struct {
 	Success bool        `json:"success"`
@@ -18,22 +17,401 @@ type Health struct {
 	Version string `json:"version,omitempty"`
 }
 
+// PathRule sends requests whose path starts with Prefix to To instead of the
+// record's default target. The longest matching prefix wins.
+type PathRule struct {
+	Prefix      string `json:"prefix" yaml:"prefix"`
+	To          string `json:"to" yaml:"to"`
+	StripPrefix bool   `json:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+}
+
+// Upstream is one backend of a record. A zero weight counts as 1.
+type Upstream struct {
+	URL    string `json:"url" yaml:"url"`
+	Weight int    `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// Load balancing policies for records with several upstreams.
+const (
+	PolicyRoundRobin     = "round_robin"
+	PolicyWeightedRandom = "weighted_random"
+	PolicyLeastConn      = "least_conn"
+	PolicyIPHash         = "ip_hash"
+	PolicyHeaderHash     = "header_hash"
+)
+
+// HealthCheck configures active probing of a record's upstreams. Durations
+// use Go syntax ("10s"); empty values fall back to the server defaults.
+type HealthCheck struct {
+	Path               string `json:"path" yaml:"path"`
+	Interval           string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout            string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	HealthyThreshold   int    `json:"healthyThreshold,omitempty" yaml:"healthyThreshold,omitempty"`
+	UnhealthyThreshold int    `json:"unhealthyThreshold,omitempty" yaml:"unhealthyThreshold,omitempty"`
+}
+
+// UpstreamHealth is the last known health check result of an upstream.
+type UpstreamHealth struct {
+	URL         string `json:"url"`
+	State       string `json:"state"`
+	LastError   string `json:"lastError,omitempty"`
+	LastChecked string `json:"lastChecked,omitempty"`
+}
+
+// CircuitBreaker configures passive outlier detection. An upstream's breaker
+// opens after ConsecutiveFailures 5xx answers, connection errors or timeouts,
+// half-opens after Cooldown and closes after SuccessThreshold good probes.
+type CircuitBreaker struct {
+	ConsecutiveFailures int    `json:"consecutiveFailures,omitempty" yaml:"consecutiveFailures,omitempty"`
+	Cooldown            string `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+	SuccessThreshold    int    `json:"successThreshold,omitempty" yaml:"successThreshold,omitempty"`
+}
+
+// BreakerStatus is the current circuit breaker state of an upstream.
+type BreakerStatus struct {
+	URL      string `json:"url"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+	OpenedAt string `json:"openedAt,omitempty"`
+}
+
+// RetryPolicy retries failed upstream requests. Attempts counts the first
+// try, RetryOn lists "connect-failure" and/or "reset" (both when empty) and
+// StatusCodes adds upstream answers that should be retried. Non-idempotent
+// methods are only retried with RetryNonIdempotent or an Idempotency-Key.
+type RetryPolicy struct {
+	Attempts           int      `json:"attempts" yaml:"attempts"`
+	RetryOn            []string `json:"retryOn,omitempty" yaml:"retryOn,omitempty"`
+	StatusCodes        []int    `json:"statusCodes,omitempty" yaml:"statusCodes,omitempty"`
+	BaseBackoff        string   `json:"baseBackoff,omitempty" yaml:"baseBackoff,omitempty"`
+	MaxBackoff         string   `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+	RetryNonIdempotent bool     `json:"retryNonIdempotent,omitempty" yaml:"retryNonIdempotent,omitempty"`
+	MaxBufferBytes     int64    `json:"maxBufferBytes,omitempty" yaml:"maxBufferBytes,omitempty"`
+}
+
+// CachePolicy opts a record into the shared HTTP response cache. DefaultTTL
+// is the heuristic freshness for responses without explicit caching headers
+// and MaxObjectBytes caps the size of a single stored response.
+type CachePolicy struct {
+	DefaultTTL     string `json:"defaultTTL,omitempty" yaml:"defaultTTL,omitempty"`
+	MaxObjectBytes int64  `json:"maxObjectBytes,omitempty" yaml:"maxObjectBytes,omitempty"`
+}
+
+// Rate limit keys. jwt_sub uses the subject verified by the record's JWT
+// edge auth. Requests without the configured header or a verified subject
+// fall back to the client IP.
+const (
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyHeader = "header"
+	RateLimitKeyJWTSub = "jwt_sub"
+)
+
+// RateLimit is a token bucket per client: Requests tokens are refilled every
+// Period (default "1s") and up to Burst (default Requests) can be spent at
+// once.
+type RateLimit struct {
+	Requests int    `json:"requests" yaml:"requests"`
+	Period   string `json:"period,omitempty" yaml:"period,omitempty"`
+	Burst    int    `json:"burst,omitempty" yaml:"burst,omitempty"`
+	Key      string `json:"key,omitempty" yaml:"key,omitempty"`
+	Header   string `json:"header,omitempty" yaml:"header,omitempty"`
+}
+
+// HeaderRules rewrite the headers of a request or response: Remove runs
+// first, then Set replaces and Add appends values. Values may contain the
+// placeholders {client_ip}, {request_id}, {host}, {method}, {path} and
+// {scheme}.
+type HeaderRules struct {
+	Set    map[string]string `json:"set,omitempty" yaml:"set,omitempty"`
+	Add    map[string]string `json:"add,omitempty" yaml:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// Record modes. A proxy record forwards requests to its upstreams, a
+// redirect record answers them with a redirect to To.
+const (
+	ModeProxy    = "proxy"
+	ModeRedirect = "redirect"
+)
+
+// Upstream protocols of a proxy record. http speaks HTTP/1.1, or HTTP/2 when
+// an https upstream offers it. http2 always speaks HTTP/2, over cleartext
+// (h2c) for http:// upstreams. grpc is http2 with streaming and errors
+// answered as gRPC statuses. tls and tcp records are proxied at layer 4:
+// tls connections are routed by the SNI of their ClientHello without
+// terminating TLS, tcp connections by the ListenPort they arrive on.
+const (
+	ProtocolHTTP  = "http"
+	ProtocolHTTP2 = "http2"
+	ProtocolGRPC  = "grpc"
+	ProtocolTLS   = "tls"
+	ProtocolTCP   = "tcp"
+)
+
+// PROXY protocol versions a record can send to its upstreams, so they see
+// the address of the client instead of prx. v1 is the text header, v2 the
+// binary one.
+const (
+	ProxyProtocolV1 = "v1"
+	ProxyProtocolV2 = "v2"
+)
+
+// RegexRewrite replaces a request path matching Match with Replacement,
+// which may refer to capture groups as $1 or ${name}. An absolute URL as
+// replacement becomes the whole redirect location.
+type RegexRewrite struct {
+	Match       string `json:"match" yaml:"match"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// RedirectRule configures a redirect record. Status defaults to 302. The
+// first matching rewrite decides the path; otherwise the request path is
+// appended to To when PreservePath is set.
+type RedirectRule struct {
+	Status        int            `json:"status,omitempty" yaml:"status,omitempty"`
+	PreservePath  bool           `json:"preservePath,omitempty" yaml:"preservePath,omitempty"`
+	PreserveQuery bool           `json:"preserveQuery,omitempty" yaml:"preserveQuery,omitempty"`
+	Rewrites      []RegexRewrite `json:"rewrites,omitempty" yaml:"rewrites,omitempty"`
+}
+
+// MirrorPolicy sends a copy of a sampled Percent (default 100) of a record's
+// requests to URL and discards the answers. Request bodies up to
+// MaxBodyBytes are copied; requests with larger bodies are not mirrored.
+type MirrorPolicy struct {
+	URL          string  `json:"url" yaml:"url"`
+	Percent      float64 `json:"percent,omitempty" yaml:"percent,omitempty"`
+	MaxBodyBytes int64   `json:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty"`
+}
+
+// MirrorStatus counts the mirrored requests of a record. Dropped requests
+// were sampled but not sent, because their body was too large or too many
+// copies were in flight.
+type MirrorStatus struct {
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+	Dropped   int64 `json:"dropped"`
+}
+
+// Certificate issuance states of records whose certificate prx obtains
+// through ACME.
+const (
+	CertificatePending = "pending"
+	CertificateIssuing = "issuing"
+	CertificateValid   = "valid"
+	CertificateFailed  = "failed"
+)
+
+// CertificateStatus reports the ACME certificate of a record. NextAttempt is
+// when it is renewed, or retried after LastError.
+type CertificateStatus struct {
+	State       string `json:"state"`
+	NotAfter    string `json:"notAfter,omitempty"`
+	LastError   string `json:"lastError,omitempty"`
+	NextAttempt string `json:"nextAttempt,omitempty"`
+}
+
+// CanaryMatch sends a request to the canary when the named request header,
+// cookie or query parameter has Value, or is present at all when Value is
+// empty. Exactly one of Header, Cookie and Query is set.
+type CanaryMatch struct {
+	Header string `json:"header,omitempty" yaml:"header,omitempty"`
+	Cookie string `json:"cookie,omitempty" yaml:"cookie,omitempty"`
+	Query  string `json:"query,omitempty" yaml:"query,omitempty"`
+	Value  string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Canary moves part of a record's traffic to To. Requests matching any of
+// Matches go to the canary; of the rest, Weight percent of the clients do.
+// Clients are assigned a bucket in the StickyCookie (default "prx_canary")
+// so they stay on the same side while the weight only grows.
+type Canary struct {
+	To           string        `json:"to" yaml:"to"`
+	Matches      []CanaryMatch `json:"matches,omitempty" yaml:"matches,omitempty"`
+	Weight       int           `json:"weight,omitempty" yaml:"weight,omitempty"`
+	StickyCookie string        `json:"stickyCookie,omitempty" yaml:"stickyCookie,omitempty"`
+}
+
+// Canary actions. Promote makes the canary target the record's target,
+// abort drops the canary.
+const (
+	CanaryPromote = "promote"
+	CanaryAbort   = "abort"
+)
+
+// Session affinity types.
+const (
+	AffinityCookie = "cookie"
+	AffinityHeader = "header"
+	AffinityIP     = "ip"
+)
+
+// SessionAffinity keeps the requests of a client on one upstream. The cookie
+// type pins a client to the upstream named in Cookie (default
+// "prx_affinity"), kept for CookieTTL or the browser session. The header and
+// ip types hash Header or the client IP onto the upstreams. Clients whose
+// upstream becomes unavailable are moved to another one.
+type SessionAffinity struct {
+	Type      string `json:"type" yaml:"type"`
+	Cookie    string `json:"cookie,omitempty" yaml:"cookie,omitempty"`
+	CookieTTL string `json:"cookieTTL,omitempty" yaml:"cookieTTL,omitempty"`
+	Header    string `json:"header,omitempty" yaml:"header,omitempty"`
+}
+
+// Edge authentication types.
+const (
+	AuthBasic   = "basic"
+	AuthJWT     = "jwt"
+	AuthForward = "forward"
+)
+
+// EdgeAuth makes a record authenticate requests before they are proxied.
+// basic checks the htpasswd data under the "auth" key of Secret, jwt checks
+// a bearer token against JWKSURL, Issuer and Audience and forward asks the
+// service at URL. Accepted requests carry the user in X-Auth-User; jwt adds
+// ClaimHeaders (header name to claim) and forward copies AuthHeaders from
+// the auth response. Claims lists claim values a JWT must have, otherwise
+// the request is forbidden.
+type EdgeAuth struct {
+	Type         string            `json:"type" yaml:"type"`
+	Realm        string            `json:"realm,omitempty" yaml:"realm,omitempty"`
+	Secret       string            `json:"secret,omitempty" yaml:"secret,omitempty"`
+	JWKSURL      string            `json:"jwksUrl,omitempty" yaml:"jwksUrl,omitempty"`
+	Issuer       string            `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Audience     string            `json:"audience,omitempty" yaml:"audience,omitempty"`
+	Claims       map[string]string `json:"claims,omitempty" yaml:"claims,omitempty"`
+	ClaimHeaders map[string]string `json:"claimHeaders,omitempty" yaml:"claimHeaders,omitempty"`
+	URL          string            `json:"url,omitempty" yaml:"url,omitempty"`
+	AuthHeaders  []string          `json:"authHeaders,omitempty" yaml:"authHeaders,omitempty"`
+}
+
+// IPAccess restricts a record to client addresses given as CIDR ranges or
+// single IPs. A matching Deny range wins; a non-empty Allow list admits only
+// the clients it contains.
+type IPAccess struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// CORS makes the proxy answer preflight requests and add CORS headers to
+// responses. AllowOrigins holds exact origins, "*", patterns where "*"
+// stands for one host label or port, or regular expressions prefixed with
+// "~". AllowMethods defaults to GET, HEAD and POST; without AllowHeaders any
+// requested header is allowed. MaxAge is in seconds.
+type CORS struct {
+	AllowOrigins     []string `json:"allowOrigins" yaml:"allowOrigins"`
+	AllowMethods     []string `json:"allowMethods,omitempty" yaml:"allowMethods,omitempty"`
+	AllowHeaders     []string `json:"allowHeaders,omitempty" yaml:"allowHeaders,omitempty"`
+	ExposeHeaders    []string `json:"exposeHeaders,omitempty" yaml:"exposeHeaders,omitempty"`
+	AllowCredentials bool     `json:"allowCredentials,omitempty" yaml:"allowCredentials,omitempty"`
+	MaxAge           int      `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+}
+
+// UpstreamTLS configures TLS towards https upstreams. CASecret names a
+// Secret whose ca.crt replaces the system roots, CertSecret a
+// kubernetes.io/tls Secret presented as client certificate. ServerName
+// overrides the SNI and the name the certificate is verified against.
+type UpstreamTLS struct {
+	CASecret           string `json:"caSecret,omitempty" yaml:"caSecret,omitempty"`
+	CertSecret         string `json:"certSecret,omitempty" yaml:"certSecret,omitempty"`
+	ServerName         string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Limits bounds the upstream exchange of a record. Empty durations and zero
+// sizes use the PRX_* defaults; a duration of "0s" removes the limit.
+// Timeout covers the whole exchange including retries, IdleTimeout is how
+// long idle upstream connections are kept.
+type Limits struct {
+	ConnectTimeout         string `json:"connectTimeout,omitempty" yaml:"connectTimeout,omitempty"`
+	ResponseHeaderTimeout  string `json:"responseHeaderTimeout,omitempty" yaml:"responseHeaderTimeout,omitempty"`
+	Timeout                string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	IdleTimeout            string `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+	MaxRequestBodyBytes    int64  `json:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty"`
+	MaxResponseHeaderBytes int64  `json:"maxResponseHeaderBytes,omitempty" yaml:"maxResponseHeaderBytes,omitempty"`
+}
+
+// Maintenance answers every request of an enabled record with a 503 and
+// Retry-After (a Go duration, default 5m) from its maintenance page instead
+// of proxying it. Message is shown on the page.
+type Maintenance struct {
+	Enabled    bool   `json:"enabled" yaml:"enabled"`
+	RetryAfter string `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty"`
+	Message    string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ProxySpec holds the optional routing settings of a record. It is embedded
+// in the API bodies and in the ConfigMap entry so every surface shares the
+// same shape.
+type ProxySpec struct {
+	Mode          string           `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Protocol      string           `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	ListenPort    int              `json:"listenPort,omitempty" yaml:"listenPort,omitempty"`
+	ProxyProtocol string           `json:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty"`
+	Redirect      *RedirectRule    `json:"redirect,omitempty" yaml:"redirect,omitempty"`
+	Routes        []PathRule       `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Upstreams     []Upstream       `json:"upstreams,omitempty" yaml:"upstreams,omitempty"`
+	Policy        string           `json:"policy,omitempty" yaml:"policy,omitempty"`
+	HashHeader    string           `json:"hashHeader,omitempty" yaml:"hashHeader,omitempty"`
+	Affinity      *SessionAffinity `json:"affinity,omitempty" yaml:"affinity,omitempty"`
+	UpstreamTLS   *UpstreamTLS     `json:"upstreamTLS,omitempty" yaml:"upstreamTLS,omitempty"`
+
+	HealthCheck     *HealthCheck    `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	CircuitBreaker  *CircuitBreaker `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	Retry           *RetryPolicy    `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Limits          *Limits         `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Cache           *CachePolicy    `json:"cache,omitempty" yaml:"cache,omitempty"`
+	RateLimit       *RateLimit      `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	RequestHeaders  *HeaderRules    `json:"requestHeaders,omitempty" yaml:"requestHeaders,omitempty"`
+	ResponseHeaders *HeaderRules    `json:"responseHeaders,omitempty" yaml:"responseHeaders,omitempty"`
+	Mirror          *MirrorPolicy   `json:"mirror,omitempty" yaml:"mirror,omitempty"`
+	Canary          *Canary         `json:"canary,omitempty" yaml:"canary,omitempty"`
+	Auth            *EdgeAuth       `json:"auth,omitempty" yaml:"auth,omitempty"`
+	IPAccess        *IPAccess       `json:"ipAccess,omitempty" yaml:"ipAccess,omitempty"`
+	CORS            *CORS           `json:"cors,omitempty" yaml:"cors,omitempty"`
+	Maintenance     *Maintenance    `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+	ErrorPages      string          `json:"errorPages,omitempty" yaml:"errorPages,omitempty"`
+}
+
 type AddNewProxy struct {
 	From string `json:"from"`
-	To   string `json:"to"`
-	Cert string `json:"cert"`
-	Key  string `json:"key"`
+	To   string `json:"to,omitempty"`
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	ProxySpec
 }
 type PatchOldProxy struct {
 	From string `json:"from"`
-	To   string `json:"to"`
-	Cert string `json:"cert"`
-	Key  string `json:"key"`
+	To   string `json:"to,omitempty"`
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	ProxySpec
 }
 type DelOldProxy struct {
 	From string `json:"from"`
 }
+type PurgeCache struct {
+	From string `json:"from"`
+	Path string `json:"path,omitempty"`
+}
+type CanaryAction struct {
+	From   string `json:"from"`
+	Action string `json:"action"`
+}
+type MaintenanceAction struct {
+	From       string `json:"from"`
+	Enabled    bool   `json:"enabled,omitempty"`
+	RetryAfter string `json:"retryAfter,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+type PurgeCacheResult struct {
+	Purged int `json:"purged"`
+}
 type RedirectionRecords struct {
 	From string `json:"from"`
 	To   string `json:"to"`
+	ProxySpec
+	Health      []UpstreamHealth   `json:"health,omitempty"`
+	Breakers    []BreakerStatus    `json:"breakers,omitempty"`
+	Mirrored    *MirrorStatus      `json:"mirrored,omitempty"`
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
 }
@@ -21,31 +21,2099 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PathRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	StripPrefix   bool                   `protobuf:"varint,3,opt,name=strip_prefix,json=stripPrefix,proto3" json:"strip_prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathRule) Reset() {
+	*x = PathRule{}
+	mi := &file_proto_reverse_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathRule) ProtoMessage() {}
+
+func (x *PathRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathRule.ProtoReflect.Descriptor instead.
+func (*PathRule) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PathRule) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *PathRule) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PathRule) GetStripPrefix() bool {
+	if x != nil {
+		return x.StripPrefix
+	}
+	return false
+}
+
+type Upstream struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Weight        int32                  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Upstream) Reset() {
+	*x = Upstream{}
+	mi := &file_proto_reverse_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Upstream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Upstream) ProtoMessage() {}
+
+func (x *Upstream) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Upstream.ProtoReflect.Descriptor instead.
+func (*Upstream) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Upstream) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Upstream) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type HealthCheck struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Path               string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Interval           string                 `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"` // Go duration, e.g. "10s"
+	Timeout            string                 `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	HealthyThreshold   int32                  `protobuf:"varint,4,opt,name=healthy_threshold,json=healthyThreshold,proto3" json:"healthy_threshold,omitempty"`
+	UnhealthyThreshold int32                  `protobuf:"varint,5,opt,name=unhealthy_threshold,json=unhealthyThreshold,proto3" json:"unhealthy_threshold,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	mi := &file_proto_reverse_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheck) ProtoMessage() {}
+
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HealthCheck) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HealthCheck) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *HealthCheck) GetTimeout() string {
+	if x != nil {
+		return x.Timeout
+	}
+	return ""
+}
+
+func (x *HealthCheck) GetHealthyThreshold() int32 {
+	if x != nil {
+		return x.HealthyThreshold
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetUnhealthyThreshold() int32 {
+	if x != nil {
+		return x.UnhealthyThreshold
+	}
+	return 0
+}
+
+type UpstreamHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // unknown, healthy, unhealthy
+	LastError     string                 `protobuf:"bytes,3,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastChecked   string                 `protobuf:"bytes,4,opt,name=last_checked,json=lastChecked,proto3" json:"last_checked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpstreamHealth) Reset() {
+	*x = UpstreamHealth{}
+	mi := &file_proto_reverse_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpstreamHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpstreamHealth) ProtoMessage() {}
+
+func (x *UpstreamHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpstreamHealth.ProtoReflect.Descriptor instead.
+func (*UpstreamHealth) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpstreamHealth) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *UpstreamHealth) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *UpstreamHealth) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *UpstreamHealth) GetLastChecked() string {
+	if x != nil {
+		return x.LastChecked
+	}
+	return ""
+}
+
+type CircuitBreaker struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ConsecutiveFailures int32                  `protobuf:"varint,1,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	Cooldown            string                 `protobuf:"bytes,2,opt,name=cooldown,proto3" json:"cooldown,omitempty"` // Go duration, e.g. "30s"
+	SuccessThreshold    int32                  `protobuf:"varint,3,opt,name=success_threshold,json=successThreshold,proto3" json:"success_threshold,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CircuitBreaker) Reset() {
+	*x = CircuitBreaker{}
+	mi := &file_proto_reverse_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CircuitBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CircuitBreaker) ProtoMessage() {}
+
+func (x *CircuitBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CircuitBreaker.ProtoReflect.Descriptor instead.
+func (*CircuitBreaker) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CircuitBreaker) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *CircuitBreaker) GetCooldown() string {
+	if x != nil {
+		return x.Cooldown
+	}
+	return ""
+}
+
+func (x *CircuitBreaker) GetSuccessThreshold() int32 {
+	if x != nil {
+		return x.SuccessThreshold
+	}
+	return 0
+}
+
+type RetryPolicy struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Attempts           int32                  `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	RetryOn            []string               `protobuf:"bytes,2,rep,name=retry_on,json=retryOn,proto3" json:"retry_on,omitempty"` // connect-failure, reset
+	StatusCodes        []int32                `protobuf:"varint,3,rep,packed,name=status_codes,json=statusCodes,proto3" json:"status_codes,omitempty"`
+	BaseBackoff        string                 `protobuf:"bytes,4,opt,name=base_backoff,json=baseBackoff,proto3" json:"base_backoff,omitempty"` // Go duration, e.g. "25ms"
+	MaxBackoff         string                 `protobuf:"bytes,5,opt,name=max_backoff,json=maxBackoff,proto3" json:"max_backoff,omitempty"`
+	RetryNonIdempotent bool                   `protobuf:"varint,6,opt,name=retry_non_idempotent,json=retryNonIdempotent,proto3" json:"retry_non_idempotent,omitempty"`
+	MaxBufferBytes     int64                  `protobuf:"varint,7,opt,name=max_buffer_bytes,json=maxBufferBytes,proto3" json:"max_buffer_bytes,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_proto_reverse_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RetryPolicy) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryOn() []string {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetStatusCodes() []int32 {
+	if x != nil {
+		return x.StatusCodes
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetBaseBackoff() string {
+	if x != nil {
+		return x.BaseBackoff
+	}
+	return ""
+}
+
+func (x *RetryPolicy) GetMaxBackoff() string {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return ""
+}
+
+func (x *RetryPolicy) GetRetryNonIdempotent() bool {
+	if x != nil {
+		return x.RetryNonIdempotent
+	}
+	return false
+}
+
+func (x *RetryPolicy) GetMaxBufferBytes() int64 {
+	if x != nil {
+		return x.MaxBufferBytes
+	}
+	return 0
+}
+
+type CachePolicy struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DefaultTtl     string                 `protobuf:"bytes,1,opt,name=default_ttl,json=defaultTtl,proto3" json:"default_ttl,omitempty"` // Go duration, e.g. "5m"
+	MaxObjectBytes int64                  `protobuf:"varint,2,opt,name=max_object_bytes,json=maxObjectBytes,proto3" json:"max_object_bytes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CachePolicy) Reset() {
+	*x = CachePolicy{}
+	mi := &file_proto_reverse_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachePolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachePolicy) ProtoMessage() {}
+
+func (x *CachePolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachePolicy.ProtoReflect.Descriptor instead.
+func (*CachePolicy) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CachePolicy) GetDefaultTtl() string {
+	if x != nil {
+		return x.DefaultTtl
+	}
+	return ""
+}
+
+func (x *CachePolicy) GetMaxObjectBytes() int64 {
+	if x != nil {
+		return x.MaxObjectBytes
+	}
+	return 0
+}
+
+type RateLimit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      int32                  `protobuf:"varint,1,opt,name=requests,proto3" json:"requests,omitempty"`
+	Period        string                 `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"` // Go duration, default "1s"
+	Burst         int32                  `protobuf:"varint,3,opt,name=burst,proto3" json:"burst,omitempty"`
+	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"` // ip, header, jwt_sub
+	Header        string                 `protobuf:"bytes,5,opt,name=header,proto3" json:"header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_proto_reverse_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RateLimit) GetRequests() int32 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *RateLimit) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *RateLimit) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+func (x *RateLimit) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RateLimit) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+type HeaderRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Set           map[string]string      `protobuf:"bytes,1,rep,name=set,proto3" json:"set,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Add           map[string]string      `protobuf:"bytes,2,rep,name=add,proto3" json:"add,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Remove        []string               `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderRules) Reset() {
+	*x = HeaderRules{}
+	mi := &file_proto_reverse_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderRules) ProtoMessage() {}
+
+func (x *HeaderRules) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderRules.ProtoReflect.Descriptor instead.
+func (*HeaderRules) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HeaderRules) GetSet() map[string]string {
+	if x != nil {
+		return x.Set
+	}
+	return nil
+}
+
+func (x *HeaderRules) GetAdd() map[string]string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *HeaderRules) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type RegexRewrite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         string                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	Replacement   string                 `protobuf:"bytes,2,opt,name=replacement,proto3" json:"replacement,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegexRewrite) Reset() {
+	*x = RegexRewrite{}
+	mi := &file_proto_reverse_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegexRewrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegexRewrite) ProtoMessage() {}
+
+func (x *RegexRewrite) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegexRewrite.ProtoReflect.Descriptor instead.
+func (*RegexRewrite) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RegexRewrite) GetMatch() string {
+	if x != nil {
+		return x.Match
+	}
+	return ""
+}
+
+func (x *RegexRewrite) GetReplacement() string {
+	if x != nil {
+		return x.Replacement
+	}
+	return ""
+}
+
+type RedirectRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"` // 301, 302, 303, 307 or 308
+	PreservePath  bool                   `protobuf:"varint,2,opt,name=preserve_path,json=preservePath,proto3" json:"preserve_path,omitempty"`
+	PreserveQuery bool                   `protobuf:"varint,3,opt,name=preserve_query,json=preserveQuery,proto3" json:"preserve_query,omitempty"`
+	Rewrites      []*RegexRewrite        `protobuf:"bytes,4,rep,name=rewrites,proto3" json:"rewrites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedirectRule) Reset() {
+	*x = RedirectRule{}
+	mi := &file_proto_reverse_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedirectRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedirectRule) ProtoMessage() {}
+
+func (x *RedirectRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedirectRule.ProtoReflect.Descriptor instead.
+func (*RedirectRule) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RedirectRule) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *RedirectRule) GetPreservePath() bool {
+	if x != nil {
+		return x.PreservePath
+	}
+	return false
+}
+
+func (x *RedirectRule) GetPreserveQuery() bool {
+	if x != nil {
+		return x.PreserveQuery
+	}
+	return false
+}
+
+func (x *RedirectRule) GetRewrites() []*RegexRewrite {
+	if x != nil {
+		return x.Rewrites
+	}
+	return nil
+}
+
+type MirrorPolicy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Percent       float64                `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"` // 0 < percent <= 100, default 100
+	MaxBodyBytes  int64                  `protobuf:"varint,3,opt,name=max_body_bytes,json=maxBodyBytes,proto3" json:"max_body_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MirrorPolicy) Reset() {
+	*x = MirrorPolicy{}
+	mi := &file_proto_reverse_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MirrorPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MirrorPolicy) ProtoMessage() {}
+
+func (x *MirrorPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MirrorPolicy.ProtoReflect.Descriptor instead.
+func (*MirrorPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MirrorPolicy) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *MirrorPolicy) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *MirrorPolicy) GetMaxBodyBytes() int64 {
+	if x != nil {
+		return x.MaxBodyBytes
+	}
+	return 0
+}
+
+type MirrorStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Succeeded     int64                  `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int64                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Dropped       int64                  `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MirrorStatus) Reset() {
+	*x = MirrorStatus{}
+	mi := &file_proto_reverse_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MirrorStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MirrorStatus) ProtoMessage() {}
+
+func (x *MirrorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MirrorStatus.ProtoReflect.Descriptor instead.
+func (*MirrorStatus) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MirrorStatus) GetSucceeded() int64 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *MirrorStatus) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *MirrorStatus) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type CertificateStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // pending, issuing, valid, failed
+	NotAfter      string                 `protobuf:"bytes,2,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	LastError     string                 `protobuf:"bytes,3,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	NextAttempt   string                 `protobuf:"bytes,4,opt,name=next_attempt,json=nextAttempt,proto3" json:"next_attempt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateStatus) Reset() {
+	*x = CertificateStatus{}
+	mi := &file_proto_reverse_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateStatus) ProtoMessage() {}
+
+func (x *CertificateStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateStatus.ProtoReflect.Descriptor instead.
+func (*CertificateStatus) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CertificateStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CertificateStatus) GetNotAfter() string {
+	if x != nil {
+		return x.NotAfter
+	}
+	return ""
+}
+
+func (x *CertificateStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *CertificateStatus) GetNextAttempt() string {
+	if x != nil {
+		return x.NextAttempt
+	}
+	return ""
+}
+
+type CanaryMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        string                 `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Cookie        string                 `protobuf:"bytes,2,opt,name=cookie,proto3" json:"cookie,omitempty"`
+	Query         string                 `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"` // empty matches any value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CanaryMatch) Reset() {
+	*x = CanaryMatch{}
+	mi := &file_proto_reverse_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CanaryMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanaryMatch) ProtoMessage() {}
+
+func (x *CanaryMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanaryMatch.ProtoReflect.Descriptor instead.
+func (*CanaryMatch) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CanaryMatch) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *CanaryMatch) GetCookie() string {
+	if x != nil {
+		return x.Cookie
+	}
+	return ""
+}
+
+func (x *CanaryMatch) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *CanaryMatch) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Canary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	To            string                 `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`
+	Matches       []*CanaryMatch         `protobuf:"bytes,2,rep,name=matches,proto3" json:"matches,omitempty"`
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"` // percent of the remaining clients
+	StickyCookie  string                 `protobuf:"bytes,4,opt,name=sticky_cookie,json=stickyCookie,proto3" json:"sticky_cookie,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Canary) Reset() {
+	*x = Canary{}
+	mi := &file_proto_reverse_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Canary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Canary) ProtoMessage() {}
+
+func (x *Canary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Canary.ProtoReflect.Descriptor instead.
+func (*Canary) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Canary) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Canary) GetMatches() []*CanaryMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *Canary) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Canary) GetStickyCookie() string {
+	if x != nil {
+		return x.StickyCookie
+	}
+	return ""
+}
+
+type SessionAffinity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // cookie, header, ip
+	Cookie        string                 `protobuf:"bytes,2,opt,name=cookie,proto3" json:"cookie,omitempty"`
+	CookieTtl     string                 `protobuf:"bytes,3,opt,name=cookie_ttl,json=cookieTtl,proto3" json:"cookie_ttl,omitempty"` // Go duration, empty for a session cookie
+	Header        string                 `protobuf:"bytes,4,opt,name=header,proto3" json:"header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionAffinity) Reset() {
+	*x = SessionAffinity{}
+	mi := &file_proto_reverse_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionAffinity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionAffinity) ProtoMessage() {}
+
+func (x *SessionAffinity) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionAffinity.ProtoReflect.Descriptor instead.
+func (*SessionAffinity) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SessionAffinity) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SessionAffinity) GetCookie() string {
+	if x != nil {
+		return x.Cookie
+	}
+	return ""
+}
+
+func (x *SessionAffinity) GetCookieTtl() string {
+	if x != nil {
+		return x.CookieTtl
+	}
+	return ""
+}
+
+func (x *SessionAffinity) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+type EdgeAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // basic, jwt, forward
+	Realm         string                 `protobuf:"bytes,2,opt,name=realm,proto3" json:"realm,omitempty"`
+	Secret        string                 `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"` // basic: Secret with htpasswd data under "auth"
+	JwksUrl       string                 `protobuf:"bytes,4,opt,name=jwks_url,json=jwksUrl,proto3" json:"jwks_url,omitempty"`
+	Issuer        string                 `protobuf:"bytes,5,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Audience      string                 `protobuf:"bytes,6,opt,name=audience,proto3" json:"audience,omitempty"`
+	Claims        map[string]string      `protobuf:"bytes,7,rep,name=claims,proto3" json:"claims,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`                                 // required claim values
+	ClaimHeaders  map[string]string      `protobuf:"bytes,8,rep,name=claim_headers,json=claimHeaders,proto3" json:"claim_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // header -> claim
+	Url           string                 `protobuf:"bytes,9,opt,name=url,proto3" json:"url,omitempty"`                                                                                                                 // forward-auth service
+	AuthHeaders   []string               `protobuf:"bytes,10,rep,name=auth_headers,json=authHeaders,proto3" json:"auth_headers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EdgeAuth) Reset() {
+	*x = EdgeAuth{}
+	mi := &file_proto_reverse_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EdgeAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EdgeAuth) ProtoMessage() {}
+
+func (x *EdgeAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EdgeAuth.ProtoReflect.Descriptor instead.
+func (*EdgeAuth) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EdgeAuth) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetRealm() string {
+	if x != nil {
+		return x.Realm
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetJwksUrl() string {
+	if x != nil {
+		return x.JwksUrl
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetClaims() map[string]string {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+func (x *EdgeAuth) GetClaimHeaders() map[string]string {
+	if x != nil {
+		return x.ClaimHeaders
+	}
+	return nil
+}
+
+func (x *EdgeAuth) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *EdgeAuth) GetAuthHeaders() []string {
+	if x != nil {
+		return x.AuthHeaders
+	}
+	return nil
+}
+
+type IPAccess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allow         []string               `protobuf:"bytes,1,rep,name=allow,proto3" json:"allow,omitempty"` // CIDR ranges or IPs
+	Deny          []string               `protobuf:"bytes,2,rep,name=deny,proto3" json:"deny,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IPAccess) Reset() {
+	*x = IPAccess{}
+	mi := &file_proto_reverse_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPAccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPAccess) ProtoMessage() {}
+
+func (x *IPAccess) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPAccess.ProtoReflect.Descriptor instead.
+func (*IPAccess) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *IPAccess) GetAllow() []string {
+	if x != nil {
+		return x.Allow
+	}
+	return nil
+}
+
+func (x *IPAccess) GetDeny() []string {
+	if x != nil {
+		return x.Deny
+	}
+	return nil
+}
+
+type CORS struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AllowOrigins     []string               `protobuf:"bytes,1,rep,name=allow_origins,json=allowOrigins,proto3" json:"allow_origins,omitempty"` // exact, "*", wildcard or ~regex
+	AllowMethods     []string               `protobuf:"bytes,2,rep,name=allow_methods,json=allowMethods,proto3" json:"allow_methods,omitempty"`
+	AllowHeaders     []string               `protobuf:"bytes,3,rep,name=allow_headers,json=allowHeaders,proto3" json:"allow_headers,omitempty"`
+	ExposeHeaders    []string               `protobuf:"bytes,4,rep,name=expose_headers,json=exposeHeaders,proto3" json:"expose_headers,omitempty"`
+	AllowCredentials bool                   `protobuf:"varint,5,opt,name=allow_credentials,json=allowCredentials,proto3" json:"allow_credentials,omitempty"`
+	MaxAge           int32                  `protobuf:"varint,6,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"` // seconds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CORS) Reset() {
+	*x = CORS{}
+	mi := &file_proto_reverse_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CORS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CORS) ProtoMessage() {}
+
+func (x *CORS) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CORS.ProtoReflect.Descriptor instead.
+func (*CORS) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CORS) GetAllowOrigins() []string {
+	if x != nil {
+		return x.AllowOrigins
+	}
+	return nil
+}
+
+func (x *CORS) GetAllowMethods() []string {
+	if x != nil {
+		return x.AllowMethods
+	}
+	return nil
+}
+
+func (x *CORS) GetAllowHeaders() []string {
+	if x != nil {
+		return x.AllowHeaders
+	}
+	return nil
+}
+
+func (x *CORS) GetExposeHeaders() []string {
+	if x != nil {
+		return x.ExposeHeaders
+	}
+	return nil
+}
+
+func (x *CORS) GetAllowCredentials() bool {
+	if x != nil {
+		return x.AllowCredentials
+	}
+	return false
+}
+
+func (x *CORS) GetMaxAge() int32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+type Limits struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	ConnectTimeout         string                 `protobuf:"bytes,1,opt,name=connect_timeout,json=connectTimeout,proto3" json:"connect_timeout,omitempty"`
+	ResponseHeaderTimeout  string                 `protobuf:"bytes,2,opt,name=response_header_timeout,json=responseHeaderTimeout,proto3" json:"response_header_timeout,omitempty"`
+	Timeout                string                 `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"` // whole upstream exchange
+	IdleTimeout            string                 `protobuf:"bytes,4,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`
+	MaxRequestBodyBytes    int64                  `protobuf:"varint,5,opt,name=max_request_body_bytes,json=maxRequestBodyBytes,proto3" json:"max_request_body_bytes,omitempty"`
+	MaxResponseHeaderBytes int64                  `protobuf:"varint,6,opt,name=max_response_header_bytes,json=maxResponseHeaderBytes,proto3" json:"max_response_header_bytes,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Limits) Reset() {
+	*x = Limits{}
+	mi := &file_proto_reverse_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limits) ProtoMessage() {}
+
+func (x *Limits) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limits.ProtoReflect.Descriptor instead.
+func (*Limits) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Limits) GetConnectTimeout() string {
+	if x != nil {
+		return x.ConnectTimeout
+	}
+	return ""
+}
+
+func (x *Limits) GetResponseHeaderTimeout() string {
+	if x != nil {
+		return x.ResponseHeaderTimeout
+	}
+	return ""
+}
+
+func (x *Limits) GetTimeout() string {
+	if x != nil {
+		return x.Timeout
+	}
+	return ""
+}
+
+func (x *Limits) GetIdleTimeout() string {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return ""
+}
+
+func (x *Limits) GetMaxRequestBodyBytes() int64 {
+	if x != nil {
+		return x.MaxRequestBodyBytes
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxResponseHeaderBytes() int64 {
+	if x != nil {
+		return x.MaxResponseHeaderBytes
+	}
+	return 0
+}
+
+type UpstreamTLS struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	CaSecret           string                 `protobuf:"bytes,1,opt,name=ca_secret,json=caSecret,proto3" json:"ca_secret,omitempty"`       // Secret with ca.crt
+	CertSecret         string                 `protobuf:"bytes,2,opt,name=cert_secret,json=certSecret,proto3" json:"cert_secret,omitempty"` // kubernetes.io/tls Secret with the client certificate
+	ServerName         string                 `protobuf:"bytes,3,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	InsecureSkipVerify bool                   `protobuf:"varint,4,opt,name=insecure_skip_verify,json=insecureSkipVerify,proto3" json:"insecure_skip_verify,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *UpstreamTLS) Reset() {
+	*x = UpstreamTLS{}
+	mi := &file_proto_reverse_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpstreamTLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpstreamTLS) ProtoMessage() {}
+
+func (x *UpstreamTLS) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpstreamTLS.ProtoReflect.Descriptor instead.
+func (*UpstreamTLS) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UpstreamTLS) GetCaSecret() string {
+	if x != nil {
+		return x.CaSecret
+	}
+	return ""
+}
+
+func (x *UpstreamTLS) GetCertSecret() string {
+	if x != nil {
+		return x.CertSecret
+	}
+	return ""
+}
+
+func (x *UpstreamTLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *UpstreamTLS) GetInsecureSkipVerify() bool {
+	if x != nil {
+		return x.InsecureSkipVerify
+	}
+	return false
+}
+
+type Maintenance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	RetryAfter    string                 `protobuf:"bytes,2,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"` // duration, e.g. 10m
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Maintenance) Reset() {
+	*x = Maintenance{}
+	mi := &file_proto_reverse_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Maintenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Maintenance) ProtoMessage() {}
+
+func (x *Maintenance) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Maintenance.ProtoReflect.Descriptor instead.
+func (*Maintenance) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Maintenance) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Maintenance) GetRetryAfter() string {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return ""
+}
+
+func (x *Maintenance) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BreakerStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // closed, open, half_open
+	Failures      int32                  `protobuf:"varint,3,opt,name=failures,proto3" json:"failures,omitempty"`
+	OpenedAt      string                 `protobuf:"bytes,4,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BreakerStatus) Reset() {
+	*x = BreakerStatus{}
+	mi := &file_proto_reverse_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BreakerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BreakerStatus) ProtoMessage() {}
+
+func (x *BreakerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BreakerStatus.ProtoReflect.Descriptor instead.
+func (*BreakerStatus) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BreakerStatus) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *BreakerStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *BreakerStatus) GetFailures() int32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *BreakerStatus) GetOpenedAt() string {
+	if x != nil {
+		return x.OpenedAt
+	}
+	return ""
+}
+
 type ProxyRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	From            string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To              string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Cert            string                 `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"` // base64
+	Key             string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`   // base64
+	Routes          []*PathRule            `protobuf:"bytes,5,rep,name=routes,proto3" json:"routes,omitempty"`
+	Upstreams       []*Upstream            `protobuf:"bytes,6,rep,name=upstreams,proto3" json:"upstreams,omitempty"`
+	Policy          string                 `protobuf:"bytes,7,opt,name=policy,proto3" json:"policy,omitempty"` // round_robin, weighted_random, least_conn, ip_hash, header_hash
+	HashHeader      string                 `protobuf:"bytes,8,opt,name=hash_header,json=hashHeader,proto3" json:"hash_header,omitempty"`
+	HealthCheck     *HealthCheck           `protobuf:"bytes,9,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
+	CircuitBreaker  *CircuitBreaker        `protobuf:"bytes,10,opt,name=circuit_breaker,json=circuitBreaker,proto3" json:"circuit_breaker,omitempty"`
+	Retry           *RetryPolicy           `protobuf:"bytes,11,opt,name=retry,proto3" json:"retry,omitempty"`
+	Cache           *CachePolicy           `protobuf:"bytes,12,opt,name=cache,proto3" json:"cache,omitempty"`
+	RateLimit       *RateLimit             `protobuf:"bytes,13,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	RequestHeaders  *HeaderRules           `protobuf:"bytes,14,opt,name=request_headers,json=requestHeaders,proto3" json:"request_headers,omitempty"`
+	ResponseHeaders *HeaderRules           `protobuf:"bytes,15,opt,name=response_headers,json=responseHeaders,proto3" json:"response_headers,omitempty"`
+	Mode            string                 `protobuf:"bytes,16,opt,name=mode,proto3" json:"mode,omitempty"` // proxy (default) or redirect
+	Redirect        *RedirectRule          `protobuf:"bytes,17,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Mirror          *MirrorPolicy          `protobuf:"bytes,18,opt,name=mirror,proto3" json:"mirror,omitempty"`
+	Canary          *Canary                `protobuf:"bytes,20,opt,name=canary,proto3" json:"canary,omitempty"`
+	Affinity        *SessionAffinity       `protobuf:"bytes,21,opt,name=affinity,proto3" json:"affinity,omitempty"`
+	Auth            *EdgeAuth              `protobuf:"bytes,22,opt,name=auth,proto3" json:"auth,omitempty"`
+	IpAccess        *IPAccess              `protobuf:"bytes,23,opt,name=ip_access,json=ipAccess,proto3" json:"ip_access,omitempty"`
+	Cors            *CORS                  `protobuf:"bytes,24,opt,name=cors,proto3" json:"cors,omitempty"`
+	Maintenance     *Maintenance           `protobuf:"bytes,25,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	ErrorPages      string                 `protobuf:"bytes,26,opt,name=error_pages,json=errorPages,proto3" json:"error_pages,omitempty"` // ConfigMap with error page templates
+	UpstreamTls     *UpstreamTLS           `protobuf:"bytes,27,opt,name=upstream_tls,json=upstreamTls,proto3" json:"upstream_tls,omitempty"`
+	Limits          *Limits                `protobuf:"bytes,28,opt,name=limits,proto3" json:"limits,omitempty"`
+	Protocol        string                 `protobuf:"bytes,29,opt,name=protocol,proto3" json:"protocol,omitempty"`                                // http (default), http2, grpc, tls or tcp
+	ListenPort      int32                  `protobuf:"varint,31,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"`         // port of a tcp record
+	ProxyProtocol   string                 `protobuf:"bytes,32,opt,name=proxy_protocol,json=proxyProtocol,proto3" json:"proxy_protocol,omitempty"` // PROXY protocol version sent to upstreams: v1 or v2
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ProxyRequest) Reset() {
+	*x = ProxyRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProxyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyRequest) ProtoMessage() {}
+
+func (x *ProxyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyRequest.ProtoReflect.Descriptor instead.
+func (*ProxyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ProxyRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetCert() string {
+	if x != nil {
+		return x.Cert
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetRoutes() []*PathRule {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetUpstreams() []*Upstream {
+	if x != nil {
+		return x.Upstreams
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetHashHeader() string {
+	if x != nil {
+		return x.HashHeader
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetHealthCheck() *HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetCircuitBreaker() *CircuitBreaker {
+	if x != nil {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetRetry() *RetryPolicy {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetCache() *CachePolicy {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetRequestHeaders() *HeaderRules {
+	if x != nil {
+		return x.RequestHeaders
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetResponseHeaders() *HeaderRules {
+	if x != nil {
+		return x.ResponseHeaders
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetRedirect() *RedirectRule {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetMirror() *MirrorPolicy {
+	if x != nil {
+		return x.Mirror
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetCanary() *Canary {
+	if x != nil {
+		return x.Canary
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetAffinity() *SessionAffinity {
+	if x != nil {
+		return x.Affinity
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetAuth() *EdgeAuth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetIpAccess() *IPAccess {
+	if x != nil {
+		return x.IpAccess
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetCors() *CORS {
+	if x != nil {
+		return x.Cors
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetMaintenance() *Maintenance {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetErrorPages() string {
+	if x != nil {
+		return x.ErrorPages
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetUpstreamTls() *UpstreamTLS {
+	if x != nil {
+		return x.UpstreamTls
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetLimits() *Limits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *ProxyRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ProxyRequest) GetListenPort() int32 {
+	if x != nil {
+		return x.ListenPort
+	}
+	return 0
+}
+
+func (x *ProxyRequest) GetProxyProtocol() string {
+	if x != nil {
+		return x.ProxyProtocol
+	}
+	return ""
+}
+
+type DeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
-	Cert          string                 `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"` // base64
-	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`   // base64
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProxyRequest) Reset() {
-	*x = ProxyRequest{}
-	mi := &file_proto_reverse_proto_msgTypes[0]
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{26}
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*ProxyRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_proto_reverse_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListResponse) GetRecords() []*ProxyRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type ProxyRecord struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	From            string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To              string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Routes          []*PathRule            `protobuf:"bytes,3,rep,name=routes,proto3" json:"routes,omitempty"`
+	Upstreams       []*Upstream            `protobuf:"bytes,4,rep,name=upstreams,proto3" json:"upstreams,omitempty"`
+	Policy          string                 `protobuf:"bytes,5,opt,name=policy,proto3" json:"policy,omitempty"`
+	HashHeader      string                 `protobuf:"bytes,6,opt,name=hash_header,json=hashHeader,proto3" json:"hash_header,omitempty"`
+	HealthCheck     *HealthCheck           `protobuf:"bytes,7,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
+	Health          []*UpstreamHealth      `protobuf:"bytes,8,rep,name=health,proto3" json:"health,omitempty"`
+	CircuitBreaker  *CircuitBreaker        `protobuf:"bytes,9,opt,name=circuit_breaker,json=circuitBreaker,proto3" json:"circuit_breaker,omitempty"`
+	Breakers        []*BreakerStatus       `protobuf:"bytes,10,rep,name=breakers,proto3" json:"breakers,omitempty"`
+	Retry           *RetryPolicy           `protobuf:"bytes,11,opt,name=retry,proto3" json:"retry,omitempty"`
+	Cache           *CachePolicy           `protobuf:"bytes,12,opt,name=cache,proto3" json:"cache,omitempty"`
+	RateLimit       *RateLimit             `protobuf:"bytes,13,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	RequestHeaders  *HeaderRules           `protobuf:"bytes,14,opt,name=request_headers,json=requestHeaders,proto3" json:"request_headers,omitempty"`
+	ResponseHeaders *HeaderRules           `protobuf:"bytes,15,opt,name=response_headers,json=responseHeaders,proto3" json:"response_headers,omitempty"`
+	Mode            string                 `protobuf:"bytes,16,opt,name=mode,proto3" json:"mode,omitempty"` // proxy (default) or redirect
+	Redirect        *RedirectRule          `protobuf:"bytes,17,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Mirror          *MirrorPolicy          `protobuf:"bytes,18,opt,name=mirror,proto3" json:"mirror,omitempty"`
+	Mirrored        *MirrorStatus          `protobuf:"bytes,19,opt,name=mirrored,proto3" json:"mirrored,omitempty"`
+	Canary          *Canary                `protobuf:"bytes,20,opt,name=canary,proto3" json:"canary,omitempty"`
+	Affinity        *SessionAffinity       `protobuf:"bytes,21,opt,name=affinity,proto3" json:"affinity,omitempty"`
+	Auth            *EdgeAuth              `protobuf:"bytes,22,opt,name=auth,proto3" json:"auth,omitempty"`
+	IpAccess        *IPAccess              `protobuf:"bytes,23,opt,name=ip_access,json=ipAccess,proto3" json:"ip_access,omitempty"`
+	Cors            *CORS                  `protobuf:"bytes,24,opt,name=cors,proto3" json:"cors,omitempty"`
+	Maintenance     *Maintenance           `protobuf:"bytes,25,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	ErrorPages      string                 `protobuf:"bytes,26,opt,name=error_pages,json=errorPages,proto3" json:"error_pages,omitempty"` // ConfigMap with error page templates
+	UpstreamTls     *UpstreamTLS           `protobuf:"bytes,27,opt,name=upstream_tls,json=upstreamTls,proto3" json:"upstream_tls,omitempty"`
+	Limits          *Limits                `protobuf:"bytes,28,opt,name=limits,proto3" json:"limits,omitempty"`
+	Protocol        string                 `protobuf:"bytes,29,opt,name=protocol,proto3" json:"protocol,omitempty"`                                // http (default), http2, grpc, tls or tcp
+	Certificate     *CertificateStatus     `protobuf:"bytes,30,opt,name=certificate,proto3" json:"certificate,omitempty"`                          // set when the certificate is issued through ACME
+	ListenPort      int32                  `protobuf:"varint,31,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"`         // port of a tcp record
+	ProxyProtocol   string                 `protobuf:"bytes,32,opt,name=proxy_protocol,json=proxyProtocol,proto3" json:"proxy_protocol,omitempty"` // PROXY protocol version sent to upstreams: v1 or v2
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ProxyRecord) Reset() {
+	*x = ProxyRecord{}
+	mi := &file_proto_reverse_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProxyRequest) String() string {
+func (x *ProxyRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProxyRequest) ProtoMessage() {}
+func (*ProxyRecord) ProtoMessage() {}
 
-func (x *ProxyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[0]
+func (x *ProxyRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,61 +2124,258 @@ func (x *ProxyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProxyRequest.ProtoReflect.Descriptor instead.
-func (*ProxyRequest) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ProxyRecord.ProtoReflect.Descriptor instead.
+func (*ProxyRecord) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *ProxyRequest) GetFrom() string {
+func (x *ProxyRecord) GetFrom() string {
 	if x != nil {
 		return x.From
 	}
 	return ""
 }
 
-func (x *ProxyRequest) GetTo() string {
+func (x *ProxyRecord) GetTo() string {
 	if x != nil {
 		return x.To
 	}
 	return ""
 }
 
-func (x *ProxyRequest) GetCert() string {
+func (x *ProxyRecord) GetRoutes() []*PathRule {
 	if x != nil {
-		return x.Cert
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetUpstreams() []*Upstream {
+	if x != nil {
+		return x.Upstreams
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetPolicy() string {
+	if x != nil {
+		return x.Policy
 	}
 	return ""
 }
 
-func (x *ProxyRequest) GetKey() string {
+func (x *ProxyRecord) GetHashHeader() string {
 	if x != nil {
-		return x.Key
+		return x.HashHeader
 	}
 	return ""
 }
 
-type DeleteRequest struct {
+func (x *ProxyRecord) GetHealthCheck() *HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetHealth() []*UpstreamHealth {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetCircuitBreaker() *CircuitBreaker {
+	if x != nil {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetBreakers() []*BreakerStatus {
+	if x != nil {
+		return x.Breakers
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetRetry() *RetryPolicy {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetCache() *CachePolicy {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetRequestHeaders() *HeaderRules {
+	if x != nil {
+		return x.RequestHeaders
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetResponseHeaders() *HeaderRules {
+	if x != nil {
+		return x.ResponseHeaders
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ProxyRecord) GetRedirect() *RedirectRule {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetMirror() *MirrorPolicy {
+	if x != nil {
+		return x.Mirror
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetMirrored() *MirrorStatus {
+	if x != nil {
+		return x.Mirrored
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetCanary() *Canary {
+	if x != nil {
+		return x.Canary
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetAffinity() *SessionAffinity {
+	if x != nil {
+		return x.Affinity
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetAuth() *EdgeAuth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetIpAccess() *IPAccess {
+	if x != nil {
+		return x.IpAccess
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetCors() *CORS {
+	if x != nil {
+		return x.Cors
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetMaintenance() *Maintenance {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetErrorPages() string {
+	if x != nil {
+		return x.ErrorPages
+	}
+	return ""
+}
+
+func (x *ProxyRecord) GetUpstreamTls() *UpstreamTLS {
+	if x != nil {
+		return x.UpstreamTls
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetLimits() *Limits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ProxyRecord) GetCertificate() *CertificateStatus {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *ProxyRecord) GetListenPort() int32 {
+	if x != nil {
+		return x.ListenPort
+	}
+	return 0
+}
+
+func (x *ProxyRecord) GetProxyProtocol() string {
+	if x != nil {
+		return x.ProxyProtocol
+	}
+	return ""
+}
+
+type PurgeCacheRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // optional path prefix, empty purges the whole host
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteRequest) Reset() {
-	*x = DeleteRequest{}
-	mi := &file_proto_reverse_proto_msgTypes[1]
+func (x *PurgeCacheRequest) Reset() {
+	*x = PurgeCacheRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRequest) String() string {
+func (x *PurgeCacheRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRequest) ProtoMessage() {}
+func (*PurgeCacheRequest) ProtoMessage() {}
 
-func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[1]
+func (x *PurgeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -121,39 +2386,47 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use PurgeCacheRequest.ProtoReflect.Descriptor instead.
+func (*PurgeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *DeleteRequest) GetFrom() string {
+func (x *PurgeCacheRequest) GetFrom() string {
 	if x != nil {
 		return x.From
 	}
 	return ""
 }
 
-type ListRequest struct {
+func (x *PurgeCacheRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type PurgeCacheResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purged        int32                  `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListRequest) Reset() {
-	*x = ListRequest{}
-	mi := &file_proto_reverse_proto_msgTypes[2]
+func (x *PurgeCacheResponse) Reset() {
+	*x = PurgeCacheResponse{}
+	mi := &file_proto_reverse_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRequest) String() string {
+func (x *PurgeCacheResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRequest) ProtoMessage() {}
+func (*PurgeCacheResponse) ProtoMessage() {}
 
-func (x *ListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[2]
+func (x *PurgeCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -164,33 +2437,41 @@ func (x *ListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
-func (*ListRequest) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use PurgeCacheResponse.ProtoReflect.Descriptor instead.
+func (*PurgeCacheResponse) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{30}
 }
 
-type ListResponse struct {
+func (x *PurgeCacheResponse) GetPurged() int32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type CanaryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Records       []*ProxyRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // promote or abort
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListResponse) Reset() {
-	*x = ListResponse{}
-	mi := &file_proto_reverse_proto_msgTypes[3]
+func (x *CanaryRequest) Reset() {
+	*x = CanaryRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListResponse) String() string {
+func (x *CanaryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResponse) ProtoMessage() {}
+func (*CanaryRequest) ProtoMessage() {}
 
-func (x *ListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[3]
+func (x *CanaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -201,41 +2482,50 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
-func (*ListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use CanaryRequest.ProtoReflect.Descriptor instead.
+func (*CanaryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *ListResponse) GetRecords() []*ProxyRecord {
+func (x *CanaryRequest) GetFrom() string {
 	if x != nil {
-		return x.Records
+		return x.From
 	}
-	return nil
+	return ""
 }
 
-type ProxyRecord struct {
+func (x *CanaryRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type MaintenanceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	RetryAfter    string                 `protobuf:"bytes,3,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProxyRecord) Reset() {
-	*x = ProxyRecord{}
-	mi := &file_proto_reverse_proto_msgTypes[4]
+func (x *MaintenanceRequest) Reset() {
+	*x = MaintenanceRequest{}
+	mi := &file_proto_reverse_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProxyRecord) String() string {
+func (x *MaintenanceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProxyRecord) ProtoMessage() {}
+func (*MaintenanceRequest) ProtoMessage() {}
 
-func (x *ProxyRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[4]
+func (x *MaintenanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_reverse_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -246,21 +2536,35 @@ func (x *ProxyRecord) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProxyRecord.ProtoReflect.Descriptor instead.
-func (*ProxyRecord) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use MaintenanceRequest.ProtoReflect.Descriptor instead.
+func (*MaintenanceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_reverse_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *ProxyRecord) GetFrom() string {
+func (x *MaintenanceRequest) GetFrom() string {
 	if x != nil {
 		return x.From
 	}
 	return ""
 }
 
-func (x *ProxyRecord) GetTo() string {
+func (x *MaintenanceRequest) GetEnabled() bool {
 	if x != nil {
-		return x.To
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *MaintenanceRequest) GetRetryAfter() string {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return ""
+}
+
+func (x *MaintenanceRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
@@ -273,7 +2577,7 @@ type Empty struct {
 
 func (x *Empty) Reset() {
 	*x = Empty{}
-	mi := &file_proto_reverse_proto_msgTypes[5]
+	mi := &file_proto_reverse_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -285,7 +2589,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_reverse_proto_msgTypes[5]
+	mi := &file_proto_reverse_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -298,28 +2602,251 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_proto_reverse_proto_rawDescGZIP(), []int{5}
+	return file_proto_reverse_proto_rawDescGZIP(), []int{33}
 }
 
 var File_proto_reverse_proto protoreflect.FileDescriptor
 
 const file_proto_reverse_proto_rawDesc = "" +
 	"\n" +
-	"\x13proto/reverse.proto\x12\x03prx\"X\n" +
+	"\x13proto/reverse.proto\x12\x03prx\"U\n" +
+	"\bPathRule\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12!\n" +
+	"\fstrip_prefix\x18\x03 \x01(\bR\vstripPrefix\"4\n" +
+	"\bUpstream\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"\xb5\x01\n" +
+	"\vHealthCheck\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\tR\binterval\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\tR\atimeout\x12+\n" +
+	"\x11healthy_threshold\x18\x04 \x01(\x05R\x10healthyThreshold\x12/\n" +
+	"\x13unhealthy_threshold\x18\x05 \x01(\x05R\x12unhealthyThreshold\"z\n" +
+	"\x0eUpstreamHealth\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x03 \x01(\tR\tlastError\x12!\n" +
+	"\flast_checked\x18\x04 \x01(\tR\vlastChecked\"\x8c\x01\n" +
+	"\x0eCircuitBreaker\x121\n" +
+	"\x14consecutive_failures\x18\x01 \x01(\x05R\x13consecutiveFailures\x12\x1a\n" +
+	"\bcooldown\x18\x02 \x01(\tR\bcooldown\x12+\n" +
+	"\x11success_threshold\x18\x03 \x01(\x05R\x10successThreshold\"\x87\x02\n" +
+	"\vRetryPolicy\x12\x1a\n" +
+	"\battempts\x18\x01 \x01(\x05R\battempts\x12\x19\n" +
+	"\bretry_on\x18\x02 \x03(\tR\aretryOn\x12!\n" +
+	"\fstatus_codes\x18\x03 \x03(\x05R\vstatusCodes\x12!\n" +
+	"\fbase_backoff\x18\x04 \x01(\tR\vbaseBackoff\x12\x1f\n" +
+	"\vmax_backoff\x18\x05 \x01(\tR\n" +
+	"maxBackoff\x120\n" +
+	"\x14retry_non_idempotent\x18\x06 \x01(\bR\x12retryNonIdempotent\x12(\n" +
+	"\x10max_buffer_bytes\x18\a \x01(\x03R\x0emaxBufferBytes\"X\n" +
+	"\vCachePolicy\x12\x1f\n" +
+	"\vdefault_ttl\x18\x01 \x01(\tR\n" +
+	"defaultTtl\x12(\n" +
+	"\x10max_object_bytes\x18\x02 \x01(\x03R\x0emaxObjectBytes\"\x7f\n" +
+	"\tRateLimit\x12\x1a\n" +
+	"\brequests\x18\x01 \x01(\x05R\brequests\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\x12\x14\n" +
+	"\x05burst\x18\x03 \x01(\x05R\x05burst\x12\x10\n" +
+	"\x03key\x18\x04 \x01(\tR\x03key\x12\x16\n" +
+	"\x06header\x18\x05 \x01(\tR\x06header\"\xef\x01\n" +
+	"\vHeaderRules\x12+\n" +
+	"\x03set\x18\x01 \x03(\v2\x19.prx.HeaderRules.SetEntryR\x03set\x12+\n" +
+	"\x03add\x18\x02 \x03(\v2\x19.prx.HeaderRules.AddEntryR\x03add\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\x1a6\n" +
+	"\bSetEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a6\n" +
+	"\bAddEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"F\n" +
+	"\fRegexRewrite\x12\x14\n" +
+	"\x05match\x18\x01 \x01(\tR\x05match\x12 \n" +
+	"\vreplacement\x18\x02 \x01(\tR\vreplacement\"\xa1\x01\n" +
+	"\fRedirectRule\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x05R\x06status\x12#\n" +
+	"\rpreserve_path\x18\x02 \x01(\bR\fpreservePath\x12%\n" +
+	"\x0epreserve_query\x18\x03 \x01(\bR\rpreserveQuery\x12-\n" +
+	"\brewrites\x18\x04 \x03(\v2\x11.prx.RegexRewriteR\brewrites\"`\n" +
+	"\fMirrorPolicy\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\x12$\n" +
+	"\x0emax_body_bytes\x18\x03 \x01(\x03R\fmaxBodyBytes\"^\n" +
+	"\fMirrorStatus\x12\x1c\n" +
+	"\tsucceeded\x18\x01 \x01(\x03R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x03R\x06failed\x12\x18\n" +
+	"\adropped\x18\x03 \x01(\x03R\adropped\"\x88\x01\n" +
+	"\x11CertificateStatus\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x1b\n" +
+	"\tnot_after\x18\x02 \x01(\tR\bnotAfter\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x03 \x01(\tR\tlastError\x12!\n" +
+	"\fnext_attempt\x18\x04 \x01(\tR\vnextAttempt\"i\n" +
+	"\vCanaryMatch\x12\x16\n" +
+	"\x06header\x18\x01 \x01(\tR\x06header\x12\x16\n" +
+	"\x06cookie\x18\x02 \x01(\tR\x06cookie\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\"\x81\x01\n" +
+	"\x06Canary\x12\x0e\n" +
+	"\x02to\x18\x01 \x01(\tR\x02to\x12*\n" +
+	"\amatches\x18\x02 \x03(\v2\x10.prx.CanaryMatchR\amatches\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\x12#\n" +
+	"\rsticky_cookie\x18\x04 \x01(\tR\fstickyCookie\"t\n" +
+	"\x0fSessionAffinity\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06cookie\x18\x02 \x01(\tR\x06cookie\x12\x1d\n" +
+	"\n" +
+	"cookie_ttl\x18\x03 \x01(\tR\tcookieTtl\x12\x16\n" +
+	"\x06header\x18\x04 \x01(\tR\x06header\"\xc5\x03\n" +
+	"\bEdgeAuth\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05realm\x18\x02 \x01(\tR\x05realm\x12\x16\n" +
+	"\x06secret\x18\x03 \x01(\tR\x06secret\x12\x19\n" +
+	"\bjwks_url\x18\x04 \x01(\tR\ajwksUrl\x12\x16\n" +
+	"\x06issuer\x18\x05 \x01(\tR\x06issuer\x12\x1a\n" +
+	"\baudience\x18\x06 \x01(\tR\baudience\x121\n" +
+	"\x06claims\x18\a \x03(\v2\x19.prx.EdgeAuth.ClaimsEntryR\x06claims\x12D\n" +
+	"\rclaim_headers\x18\b \x03(\v2\x1f.prx.EdgeAuth.ClaimHeadersEntryR\fclaimHeaders\x12\x10\n" +
+	"\x03url\x18\t \x01(\tR\x03url\x12!\n" +
+	"\fauth_headers\x18\n" +
+	" \x03(\tR\vauthHeaders\x1a9\n" +
+	"\vClaimsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11ClaimHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"4\n" +
+	"\bIPAccess\x12\x14\n" +
+	"\x05allow\x18\x01 \x03(\tR\x05allow\x12\x12\n" +
+	"\x04deny\x18\x02 \x03(\tR\x04deny\"\xe2\x01\n" +
+	"\x04CORS\x12#\n" +
+	"\rallow_origins\x18\x01 \x03(\tR\fallowOrigins\x12#\n" +
+	"\rallow_methods\x18\x02 \x03(\tR\fallowMethods\x12#\n" +
+	"\rallow_headers\x18\x03 \x03(\tR\fallowHeaders\x12%\n" +
+	"\x0eexpose_headers\x18\x04 \x03(\tR\rexposeHeaders\x12+\n" +
+	"\x11allow_credentials\x18\x05 \x01(\bR\x10allowCredentials\x12\x17\n" +
+	"\amax_age\x18\x06 \x01(\x05R\x06maxAge\"\x96\x02\n" +
+	"\x06Limits\x12'\n" +
+	"\x0fconnect_timeout\x18\x01 \x01(\tR\x0econnectTimeout\x126\n" +
+	"\x17response_header_timeout\x18\x02 \x01(\tR\x15responseHeaderTimeout\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\tR\atimeout\x12!\n" +
+	"\fidle_timeout\x18\x04 \x01(\tR\vidleTimeout\x123\n" +
+	"\x16max_request_body_bytes\x18\x05 \x01(\x03R\x13maxRequestBodyBytes\x129\n" +
+	"\x19max_response_header_bytes\x18\x06 \x01(\x03R\x16maxResponseHeaderBytes\"\x9e\x01\n" +
+	"\vUpstreamTLS\x12\x1b\n" +
+	"\tca_secret\x18\x01 \x01(\tR\bcaSecret\x12\x1f\n" +
+	"\vcert_secret\x18\x02 \x01(\tR\n" +
+	"certSecret\x12\x1f\n" +
+	"\vserver_name\x18\x03 \x01(\tR\n" +
+	"serverName\x120\n" +
+	"\x14insecure_skip_verify\x18\x04 \x01(\bR\x12insecureSkipVerify\"b\n" +
+	"\vMaintenance\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vretry_after\x18\x02 \x01(\tR\n" +
+	"retryAfter\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"p\n" +
+	"\rBreakerStatus\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1a\n" +
+	"\bfailures\x18\x03 \x01(\x05R\bfailures\x12\x1b\n" +
+	"\topened_at\x18\x04 \x01(\tR\bopenedAt\"\x95\t\n" +
 	"\fProxyRequest\x12\x12\n" +
 	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
 	"\x02to\x18\x02 \x01(\tR\x02to\x12\x12\n" +
 	"\x04cert\x18\x03 \x01(\tR\x04cert\x12\x10\n" +
-	"\x03key\x18\x04 \x01(\tR\x03key\"#\n" +
+	"\x03key\x18\x04 \x01(\tR\x03key\x12%\n" +
+	"\x06routes\x18\x05 \x03(\v2\r.prx.PathRuleR\x06routes\x12+\n" +
+	"\tupstreams\x18\x06 \x03(\v2\r.prx.UpstreamR\tupstreams\x12\x16\n" +
+	"\x06policy\x18\a \x01(\tR\x06policy\x12\x1f\n" +
+	"\vhash_header\x18\b \x01(\tR\n" +
+	"hashHeader\x123\n" +
+	"\fhealth_check\x18\t \x01(\v2\x10.prx.HealthCheckR\vhealthCheck\x12<\n" +
+	"\x0fcircuit_breaker\x18\n" +
+	" \x01(\v2\x13.prx.CircuitBreakerR\x0ecircuitBreaker\x12&\n" +
+	"\x05retry\x18\v \x01(\v2\x10.prx.RetryPolicyR\x05retry\x12&\n" +
+	"\x05cache\x18\f \x01(\v2\x10.prx.CachePolicyR\x05cache\x12-\n" +
+	"\n" +
+	"rate_limit\x18\r \x01(\v2\x0e.prx.RateLimitR\trateLimit\x129\n" +
+	"\x0frequest_headers\x18\x0e \x01(\v2\x10.prx.HeaderRulesR\x0erequestHeaders\x12;\n" +
+	"\x10response_headers\x18\x0f \x01(\v2\x10.prx.HeaderRulesR\x0fresponseHeaders\x12\x12\n" +
+	"\x04mode\x18\x10 \x01(\tR\x04mode\x12-\n" +
+	"\bredirect\x18\x11 \x01(\v2\x11.prx.RedirectRuleR\bredirect\x12)\n" +
+	"\x06mirror\x18\x12 \x01(\v2\x11.prx.MirrorPolicyR\x06mirror\x12#\n" +
+	"\x06canary\x18\x14 \x01(\v2\v.prx.CanaryR\x06canary\x120\n" +
+	"\baffinity\x18\x15 \x01(\v2\x14.prx.SessionAffinityR\baffinity\x12!\n" +
+	"\x04auth\x18\x16 \x01(\v2\r.prx.EdgeAuthR\x04auth\x12*\n" +
+	"\tip_access\x18\x17 \x01(\v2\r.prx.IPAccessR\bipAccess\x12\x1d\n" +
+	"\x04cors\x18\x18 \x01(\v2\t.prx.CORSR\x04cors\x122\n" +
+	"\vmaintenance\x18\x19 \x01(\v2\x10.prx.MaintenanceR\vmaintenance\x12\x1f\n" +
+	"\verror_pages\x18\x1a \x01(\tR\n" +
+	"errorPages\x123\n" +
+	"\fupstream_tls\x18\x1b \x01(\v2\x10.prx.UpstreamTLSR\vupstreamTls\x12#\n" +
+	"\x06limits\x18\x1c \x01(\v2\v.prx.LimitsR\x06limits\x12\x1a\n" +
+	"\bprotocol\x18\x1d \x01(\tR\bprotocol\x12\x1f\n" +
+	"\vlisten_port\x18\x1f \x01(\x05R\n" +
+	"listenPort\x12%\n" +
+	"\x0eproxy_protocol\x18  \x01(\tR\rproxyProtocol\"#\n" +
 	"\rDeleteRequest\x12\x12\n" +
 	"\x04from\x18\x01 \x01(\tR\x04from\"\r\n" +
 	"\vListRequest\":\n" +
 	"\fListResponse\x12*\n" +
-	"\arecords\x18\x01 \x03(\v2\x10.prx.ProxyRecordR\arecords\"1\n" +
+	"\arecords\x18\x01 \x03(\v2\x10.prx.ProxyRecordR\arecords\"\xb4\n" +
+	"\n" +
 	"\vProxyRecord\x12\x12\n" +
 	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
-	"\x02to\x18\x02 \x01(\tR\x02to\"\a\n" +
-	"\x05Empty2\xaf\x01\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12%\n" +
+	"\x06routes\x18\x03 \x03(\v2\r.prx.PathRuleR\x06routes\x12+\n" +
+	"\tupstreams\x18\x04 \x03(\v2\r.prx.UpstreamR\tupstreams\x12\x16\n" +
+	"\x06policy\x18\x05 \x01(\tR\x06policy\x12\x1f\n" +
+	"\vhash_header\x18\x06 \x01(\tR\n" +
+	"hashHeader\x123\n" +
+	"\fhealth_check\x18\a \x01(\v2\x10.prx.HealthCheckR\vhealthCheck\x12+\n" +
+	"\x06health\x18\b \x03(\v2\x13.prx.UpstreamHealthR\x06health\x12<\n" +
+	"\x0fcircuit_breaker\x18\t \x01(\v2\x13.prx.CircuitBreakerR\x0ecircuitBreaker\x12.\n" +
+	"\bbreakers\x18\n" +
+	" \x03(\v2\x12.prx.BreakerStatusR\bbreakers\x12&\n" +
+	"\x05retry\x18\v \x01(\v2\x10.prx.RetryPolicyR\x05retry\x12&\n" +
+	"\x05cache\x18\f \x01(\v2\x10.prx.CachePolicyR\x05cache\x12-\n" +
+	"\n" +
+	"rate_limit\x18\r \x01(\v2\x0e.prx.RateLimitR\trateLimit\x129\n" +
+	"\x0frequest_headers\x18\x0e \x01(\v2\x10.prx.HeaderRulesR\x0erequestHeaders\x12;\n" +
+	"\x10response_headers\x18\x0f \x01(\v2\x10.prx.HeaderRulesR\x0fresponseHeaders\x12\x12\n" +
+	"\x04mode\x18\x10 \x01(\tR\x04mode\x12-\n" +
+	"\bredirect\x18\x11 \x01(\v2\x11.prx.RedirectRuleR\bredirect\x12)\n" +
+	"\x06mirror\x18\x12 \x01(\v2\x11.prx.MirrorPolicyR\x06mirror\x12-\n" +
+	"\bmirrored\x18\x13 \x01(\v2\x11.prx.MirrorStatusR\bmirrored\x12#\n" +
+	"\x06canary\x18\x14 \x01(\v2\v.prx.CanaryR\x06canary\x120\n" +
+	"\baffinity\x18\x15 \x01(\v2\x14.prx.SessionAffinityR\baffinity\x12!\n" +
+	"\x04auth\x18\x16 \x01(\v2\r.prx.EdgeAuthR\x04auth\x12*\n" +
+	"\tip_access\x18\x17 \x01(\v2\r.prx.IPAccessR\bipAccess\x12\x1d\n" +
+	"\x04cors\x18\x18 \x01(\v2\t.prx.CORSR\x04cors\x122\n" +
+	"\vmaintenance\x18\x19 \x01(\v2\x10.prx.MaintenanceR\vmaintenance\x12\x1f\n" +
+	"\verror_pages\x18\x1a \x01(\tR\n" +
+	"errorPages\x123\n" +
+	"\fupstream_tls\x18\x1b \x01(\v2\x10.prx.UpstreamTLSR\vupstreamTls\x12#\n" +
+	"\x06limits\x18\x1c \x01(\v2\v.prx.LimitsR\x06limits\x12\x1a\n" +
+	"\bprotocol\x18\x1d \x01(\tR\bprotocol\x128\n" +
+	"\vcertificate\x18\x1e \x01(\v2\x16.prx.CertificateStatusR\vcertificate\x12\x1f\n" +
+	"\vlisten_port\x18\x1f \x01(\x05R\n" +
+	"listenPort\x12%\n" +
+	"\x0eproxy_protocol\x18  \x01(\tR\rproxyProtocol\";\n" +
+	"\x11PurgeCacheRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\",\n" +
+	"\x12PurgeCacheResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x05R\x06purged\";\n" +
+	"\rCanaryRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\"}\n" +
+	"\x12MaintenanceRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vretry_after\x18\x03 \x01(\tR\n" +
+	"retryAfter\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\a\n" +
+	"\x05Empty2\xcc\x02\n" +
 	"\aReverse\x12$\n" +
 	"\x03Add\x12\x11.prx.ProxyRequest\x1a\n" +
 	".prx.Empty\x12'\n" +
@@ -327,7 +2854,13 @@ const file_proto_reverse_proto_rawDesc = "" +
 	".prx.Empty\x12(\n" +
 	"\x06Delete\x12\x12.prx.DeleteRequest\x1a\n" +
 	".prx.Empty\x12+\n" +
-	"\x04List\x12\x10.prx.ListRequest\x1a\x11.prx.ListResponseB\x10Z\x0einternal/pb;pbb\x06proto3"
+	"\x04List\x12\x10.prx.ListRequest\x1a\x11.prx.ListResponse\x12=\n" +
+	"\n" +
+	"PurgeCache\x12\x16.prx.PurgeCacheRequest\x1a\x17.prx.PurgeCacheResponse\x12(\n" +
+	"\x06Canary\x12\x12.prx.CanaryRequest\x1a\n" +
+	".prx.Empty\x122\n" +
+	"\vMaintenance\x12\x17.prx.MaintenanceRequest\x1a\n" +
+	".prx.EmptyB\x10Z\x0einternal/pb;pbb\x06proto3"
 
 var (
 	file_proto_reverse_proto_rawDescOnce sync.Once
@@ -341,30 +2874,116 @@ func file_proto_reverse_proto_rawDescGZIP() []byte {
 	return file_proto_reverse_proto_rawDescData
 }
 
-var file_proto_reverse_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_reverse_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_proto_reverse_proto_goTypes = []any{
-	(*ProxyRequest)(nil),  // 0: prx.ProxyRequest
-	(*DeleteRequest)(nil), // 1: prx.DeleteRequest
-	(*ListRequest)(nil),   // 2: prx.ListRequest
-	(*ListResponse)(nil),  // 3: prx.ListResponse
-	(*ProxyRecord)(nil),   // 4: prx.ProxyRecord
-	(*Empty)(nil),         // 5: prx.Empty
+	(*PathRule)(nil),           // 0: prx.PathRule
+	(*Upstream)(nil),           // 1: prx.Upstream
+	(*HealthCheck)(nil),        // 2: prx.HealthCheck
+	(*UpstreamHealth)(nil),     // 3: prx.UpstreamHealth
+	(*CircuitBreaker)(nil),     // 4: prx.CircuitBreaker
+	(*RetryPolicy)(nil),        // 5: prx.RetryPolicy
+	(*CachePolicy)(nil),        // 6: prx.CachePolicy
+	(*RateLimit)(nil),          // 7: prx.RateLimit
+	(*HeaderRules)(nil),        // 8: prx.HeaderRules
+	(*RegexRewrite)(nil),       // 9: prx.RegexRewrite
+	(*RedirectRule)(nil),       // 10: prx.RedirectRule
+	(*MirrorPolicy)(nil),       // 11: prx.MirrorPolicy
+	(*MirrorStatus)(nil),       // 12: prx.MirrorStatus
+	(*CertificateStatus)(nil),  // 13: prx.CertificateStatus
+	(*CanaryMatch)(nil),        // 14: prx.CanaryMatch
+	(*Canary)(nil),             // 15: prx.Canary
+	(*SessionAffinity)(nil),    // 16: prx.SessionAffinity
+	(*EdgeAuth)(nil),           // 17: prx.EdgeAuth
+	(*IPAccess)(nil),           // 18: prx.IPAccess
+	(*CORS)(nil),               // 19: prx.CORS
+	(*Limits)(nil),             // 20: prx.Limits
+	(*UpstreamTLS)(nil),        // 21: prx.UpstreamTLS
+	(*Maintenance)(nil),        // 22: prx.Maintenance
+	(*BreakerStatus)(nil),      // 23: prx.BreakerStatus
+	(*ProxyRequest)(nil),       // 24: prx.ProxyRequest
+	(*DeleteRequest)(nil),      // 25: prx.DeleteRequest
+	(*ListRequest)(nil),        // 26: prx.ListRequest
+	(*ListResponse)(nil),       // 27: prx.ListResponse
+	(*ProxyRecord)(nil),        // 28: prx.ProxyRecord
+	(*PurgeCacheRequest)(nil),  // 29: prx.PurgeCacheRequest
+	(*PurgeCacheResponse)(nil), // 30: prx.PurgeCacheResponse
+	(*CanaryRequest)(nil),      // 31: prx.CanaryRequest
+	(*MaintenanceRequest)(nil), // 32: prx.MaintenanceRequest
+	(*Empty)(nil),              // 33: prx.Empty
+	nil,                        // 34: prx.HeaderRules.SetEntry
+	nil,                        // 35: prx.HeaderRules.AddEntry
+	nil,                        // 36: prx.EdgeAuth.ClaimsEntry
+	nil,                        // 37: prx.EdgeAuth.ClaimHeadersEntry
 }
 var file_proto_reverse_proto_depIdxs = []int32{
-	4, // 0: prx.ListResponse.records:type_name -> prx.ProxyRecord
-	0, // 1: prx.Reverse.Add:input_type -> prx.ProxyRequest
-	0, // 2: prx.Reverse.Update:input_type -> prx.ProxyRequest
-	1, // 3: prx.Reverse.Delete:input_type -> prx.DeleteRequest
-	2, // 4: prx.Reverse.List:input_type -> prx.ListRequest
-	5, // 5: prx.Reverse.Add:output_type -> prx.Empty
-	5, // 6: prx.Reverse.Update:output_type -> prx.Empty
-	5, // 7: prx.Reverse.Delete:output_type -> prx.Empty
-	3, // 8: prx.Reverse.List:output_type -> prx.ListResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	34, // 0: prx.HeaderRules.set:type_name -> prx.HeaderRules.SetEntry
+	35, // 1: prx.HeaderRules.add:type_name -> prx.HeaderRules.AddEntry
+	9,  // 2: prx.RedirectRule.rewrites:type_name -> prx.RegexRewrite
+	14, // 3: prx.Canary.matches:type_name -> prx.CanaryMatch
+	36, // 4: prx.EdgeAuth.claims:type_name -> prx.EdgeAuth.ClaimsEntry
+	37, // 5: prx.EdgeAuth.claim_headers:type_name -> prx.EdgeAuth.ClaimHeadersEntry
+	0,  // 6: prx.ProxyRequest.routes:type_name -> prx.PathRule
+	1,  // 7: prx.ProxyRequest.upstreams:type_name -> prx.Upstream
+	2,  // 8: prx.ProxyRequest.health_check:type_name -> prx.HealthCheck
+	4,  // 9: prx.ProxyRequest.circuit_breaker:type_name -> prx.CircuitBreaker
+	5,  // 10: prx.ProxyRequest.retry:type_name -> prx.RetryPolicy
+	6,  // 11: prx.ProxyRequest.cache:type_name -> prx.CachePolicy
+	7,  // 12: prx.ProxyRequest.rate_limit:type_name -> prx.RateLimit
+	8,  // 13: prx.ProxyRequest.request_headers:type_name -> prx.HeaderRules
+	8,  // 14: prx.ProxyRequest.response_headers:type_name -> prx.HeaderRules
+	10, // 15: prx.ProxyRequest.redirect:type_name -> prx.RedirectRule
+	11, // 16: prx.ProxyRequest.mirror:type_name -> prx.MirrorPolicy
+	15, // 17: prx.ProxyRequest.canary:type_name -> prx.Canary
+	16, // 18: prx.ProxyRequest.affinity:type_name -> prx.SessionAffinity
+	17, // 19: prx.ProxyRequest.auth:type_name -> prx.EdgeAuth
+	18, // 20: prx.ProxyRequest.ip_access:type_name -> prx.IPAccess
+	19, // 21: prx.ProxyRequest.cors:type_name -> prx.CORS
+	22, // 22: prx.ProxyRequest.maintenance:type_name -> prx.Maintenance
+	21, // 23: prx.ProxyRequest.upstream_tls:type_name -> prx.UpstreamTLS
+	20, // 24: prx.ProxyRequest.limits:type_name -> prx.Limits
+	28, // 25: prx.ListResponse.records:type_name -> prx.ProxyRecord
+	0,  // 26: prx.ProxyRecord.routes:type_name -> prx.PathRule
+	1,  // 27: prx.ProxyRecord.upstreams:type_name -> prx.Upstream
+	2,  // 28: prx.ProxyRecord.health_check:type_name -> prx.HealthCheck
+	3,  // 29: prx.ProxyRecord.health:type_name -> prx.UpstreamHealth
+	4,  // 30: prx.ProxyRecord.circuit_breaker:type_name -> prx.CircuitBreaker
+	23, // 31: prx.ProxyRecord.breakers:type_name -> prx.BreakerStatus
+	5,  // 32: prx.ProxyRecord.retry:type_name -> prx.RetryPolicy
+	6,  // 33: prx.ProxyRecord.cache:type_name -> prx.CachePolicy
+	7,  // 34: prx.ProxyRecord.rate_limit:type_name -> prx.RateLimit
+	8,  // 35: prx.ProxyRecord.request_headers:type_name -> prx.HeaderRules
+	8,  // 36: prx.ProxyRecord.response_headers:type_name -> prx.HeaderRules
+	10, // 37: prx.ProxyRecord.redirect:type_name -> prx.RedirectRule
+	11, // 38: prx.ProxyRecord.mirror:type_name -> prx.MirrorPolicy
+	12, // 39: prx.ProxyRecord.mirrored:type_name -> prx.MirrorStatus
+	15, // 40: prx.ProxyRecord.canary:type_name -> prx.Canary
+	16, // 41: prx.ProxyRecord.affinity:type_name -> prx.SessionAffinity
+	17, // 42: prx.ProxyRecord.auth:type_name -> prx.EdgeAuth
+	18, // 43: prx.ProxyRecord.ip_access:type_name -> prx.IPAccess
+	19, // 44: prx.ProxyRecord.cors:type_name -> prx.CORS
+	22, // 45: prx.ProxyRecord.maintenance:type_name -> prx.Maintenance
+	21, // 46: prx.ProxyRecord.upstream_tls:type_name -> prx.UpstreamTLS
+	20, // 47: prx.ProxyRecord.limits:type_name -> prx.Limits
+	13, // 48: prx.ProxyRecord.certificate:type_name -> prx.CertificateStatus
+	24, // 49: prx.Reverse.Add:input_type -> prx.ProxyRequest
+	24, // 50: prx.Reverse.Update:input_type -> prx.ProxyRequest
+	25, // 51: prx.Reverse.Delete:input_type -> prx.DeleteRequest
+	26, // 52: prx.Reverse.List:input_type -> prx.ListRequest
+	29, // 53: prx.Reverse.PurgeCache:input_type -> prx.PurgeCacheRequest
+	31, // 54: prx.Reverse.Canary:input_type -> prx.CanaryRequest
+	32, // 55: prx.Reverse.Maintenance:input_type -> prx.MaintenanceRequest
+	33, // 56: prx.Reverse.Add:output_type -> prx.Empty
+	33, // 57: prx.Reverse.Update:output_type -> prx.Empty
+	33, // 58: prx.Reverse.Delete:output_type -> prx.Empty
+	27, // 59: prx.Reverse.List:output_type -> prx.ListResponse
+	30, // 60: prx.Reverse.PurgeCache:output_type -> prx.PurgeCacheResponse
+	33, // 61: prx.Reverse.Canary:output_type -> prx.Empty
+	33, // 62: prx.Reverse.Maintenance:output_type -> prx.Empty
+	56, // [56:63] is the sub-list for method output_type
+	49, // [49:56] is the sub-list for method input_type
+	49, // [49:49] is the sub-list for extension type_name
+	49, // [49:49] is the sub-list for extension extendee
+	0,  // [0:49] is the sub-list for field type_name
 }
 
 func init() { file_proto_reverse_proto_init() }
@@ -378,7 +2997,7 @@ func file_proto_reverse_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_reverse_proto_rawDesc), len(file_proto_reverse_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   38,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,10 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Reverse_Add_FullMethodName    = "/prx.Reverse/Add"
-	Reverse_Update_FullMethodName = "/prx.Reverse/Update"
-	Reverse_Delete_FullMethodName = "/prx.Reverse/Delete"
-	Reverse_List_FullMethodName   = "/prx.Reverse/List"
+	Reverse_Add_FullMethodName         = "/prx.Reverse/Add"
+	Reverse_Update_FullMethodName      = "/prx.Reverse/Update"
+	Reverse_Delete_FullMethodName      = "/prx.Reverse/Delete"
+	Reverse_List_FullMethodName        = "/prx.Reverse/List"
+	Reverse_PurgeCache_FullMethodName  = "/prx.Reverse/PurgeCache"
+	Reverse_Canary_FullMethodName      = "/prx.Reverse/Canary"
+	Reverse_Maintenance_FullMethodName = "/prx.Reverse/Maintenance"
 )
 
 // ReverseClient is the client API for Reverse service.
@@ -33,6 +36,9 @@ type ReverseClient interface {
 	Update(ctx context.Context, in *ProxyRequest, opts ...grpc.CallOption) (*Empty, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Empty, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error)
+	Canary(ctx context.Context, in *CanaryRequest, opts ...grpc.CallOption) (*Empty, error)
+	Maintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type reverseClient struct {
@@ -83,6 +89,36 @@ func (c *reverseClient) List(ctx context.Context, in *ListRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *reverseClient) PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeCacheResponse)
+	err := c.cc.Invoke(ctx, Reverse_PurgeCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reverseClient) Canary(ctx context.Context, in *CanaryRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Reverse_Canary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reverseClient) Maintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Reverse_Maintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReverseServer is the server API for Reverse service.
 // All implementations must embed UnimplementedReverseServer
 // for forward compatibility.
@@ -91,6 +127,9 @@ type ReverseServer interface {
 	Update(context.Context, *ProxyRequest) (*Empty, error)
 	Delete(context.Context, *DeleteRequest) (*Empty, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
+	PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error)
+	Canary(context.Context, *CanaryRequest) (*Empty, error)
+	Maintenance(context.Context, *MaintenanceRequest) (*Empty, error)
 	mustEmbedUnimplementedReverseServer()
 }
 
@@ -113,6 +152,15 @@ func (UnimplementedReverseServer) Delete(context.Context, *DeleteRequest) (*Empt
 func (UnimplementedReverseServer) List(context.Context, *ListRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedReverseServer) PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeCache not implemented")
+}
+func (UnimplementedReverseServer) Canary(context.Context, *CanaryRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Canary not implemented")
+}
+func (UnimplementedReverseServer) Maintenance(context.Context, *MaintenanceRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Maintenance not implemented")
+}
 func (UnimplementedReverseServer) mustEmbedUnimplementedReverseServer() {}
 func (UnimplementedReverseServer) testEmbeddedByValue()                 {}
 
@@ -206,6 +254,60 @@ func _Reverse_List_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Reverse_PurgeCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReverseServer).PurgeCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reverse_PurgeCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReverseServer).PurgeCache(ctx, req.(*PurgeCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reverse_Canary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CanaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReverseServer).Canary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reverse_Canary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReverseServer).Canary(ctx, req.(*CanaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reverse_Maintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReverseServer).Maintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reverse_Maintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReverseServer).Maintenance(ctx, req.(*MaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Reverse_ServiceDesc is the grpc.ServiceDesc for Reverse service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +331,18 @@ var Reverse_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _Reverse_List_Handler,
 		},
+		{
+			MethodName: "PurgeCache",
+			Handler:    _Reverse_PurgeCache_Handler,
+		},
+		{
+			MethodName: "Canary",
+			Handler:    _Reverse_Canary_Handler,
+		},
+		{
+			MethodName: "Maintenance",
+			Handler:    _Reverse_Maintenance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/reverse.proto",
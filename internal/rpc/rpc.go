@@ -20,13 +20,52 @@ import (
 
 func Run(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <add|update|delete|list> [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s <add|update|delete|list|cache|canary|maintenance> [flags]\n", os.Args[0])
 		os.Exit(1)
 	}
 	subcmd := args[0]
 
 	var err error
-	var addr, token, from, to, certPath, keyPath *string
+	var addr, token, from, to, certPath, keyPath, policy, hashHeader *string
+	var healthPath, healthInterval, healthTimeout *string
+	var healthyThreshold, unhealthyThreshold *int
+	var breakerFailures, breakerSuccesses *int
+	var breakerCooldown *string
+	var retryAttempts *int
+	var retryOn, retryStatus, retryBackoff, retryMaxBackoff *string
+	var retryNonIdempotent *bool
+	var retryBufferBytes *int64
+	var routes, upstreams stringList
+	var affinity, affinityCookie, affinityTTL, affinityHeader *string
+	var reqSet, reqAdd, reqRemove, resSet, resAdd, resRemove stringList
+	var mode, protocol, proxyProtocol *string
+	var redirectStatus, listenPort *int
+	var preservePath, preserveQuery *bool
+	var rewrites stringList
+	var cacheEnabled *bool
+	var cacheTTL, purgePath *string
+	var cacheMaxObject *int64
+	var rateRequests, rateBurst *int
+	var ratePeriod, rateKey, rateHeader *string
+	var mirrorURL *string
+	var mirrorPercent *float64
+	var mirrorBodyBytes *int64
+	var canaryTo, canaryCookieName, canaryAction *string
+	var canaryWeight *int
+	var canaryHeaders, canaryCookies, canaryQueries stringList
+	var authType, authRealm, authSecret, authJWKS, authIssuer, authAudience, authURL *string
+	var authClaims, authClaimHeaders, authHeaders stringList
+	var allowCIDRs, denyCIDRs stringList
+	var corsOrigins stringList
+	var maintenance *bool
+	var upstreamCASecret, upstreamCertSecret, upstreamServerName *string
+	var upstreamInsecure *bool
+	var connectTimeout, responseHeaderTimeout, requestTimeout, idleTimeout *string
+	var maxBodyBytes, maxResponseHeaderBytes *int64
+	var maintenanceRetryAfter, maintenanceMessage, errorPages *string
+	var corsMethods, corsHeaders, corsExpose *string
+	var corsCredentials *bool
+	var corsMaxAge *int
 	switch subcmd {
 	case "add", "update":
 		fs := flag.NewFlagSet(subcmd, flag.ExitOnError)
@@ -34,8 +73,94 @@ func Run(args []string) {
 		token = fs.String("token", os.Getenv("PROXY_TOKEN"), "JWT bearer token")
 		from = fs.String("from", "", "source host")
 		to = fs.String("to", "", "target URL")
-		certPath = fs.String("cert", "", "path to TLS cert")
+		certPath = fs.String("cert", "", "path to TLS cert (omit with --key to issue it through ACME)")
 		keyPath = fs.String("key", "", "path to TLS key")
+		mode = fs.String("mode", "", "record mode: proxy (default) or redirect")
+		protocol = fs.String("protocol", "", "upstream protocol: http (default), http2, grpc, tls (SNI passthrough) or tcp")
+		listenPort = fs.Int("listen-port", 0, "port prx accepts the connections of a tcp record on")
+		proxyProtocol = fs.String("proxy-protocol", "", "send the client address to upstreams with PROXY protocol v1 or v2")
+		redirectStatus = fs.Int("redirect-status", 0, "redirect status code: 301, 302, 303, 307 or 308 (default 302)")
+		preservePath = fs.Bool("preserve-path", false, "append the request path to the redirect target")
+		preserveQuery = fs.Bool("preserve-query", false, "append the request query to the redirect target")
+		fs.Var(&rewrites, "rewrite", "redirect path rewrite REGEX=REPLACEMENT, $1 refers to capture groups (repeatable)")
+		fs.Var(&routes, "route", "path rule PREFIX=URL[,strip] (repeatable)")
+		fs.Var(&upstreams, "upstream", "upstream URL[,WEIGHT] (repeatable, replaces --to)")
+		policy = fs.String("policy", "", "load balancing policy: round_robin, weighted_random, least_conn, ip_hash, header_hash")
+		hashHeader = fs.String("hash-header", "", "header to hash on for the header_hash policy")
+		affinity = fs.String("affinity", "", "session affinity: cookie, header, ip")
+		affinityCookie = fs.String("affinity-cookie", "", "cookie pinning clients for cookie affinity (default prx_affinity)")
+		affinityTTL = fs.String("affinity-ttl", "", "lifetime of the affinity cookie (default browser session)")
+		affinityHeader = fs.String("affinity-header", "", "header to hash on for header affinity")
+		healthPath = fs.String("health-path", "", "HTTP path to probe on each upstream, enables health checks")
+		healthInterval = fs.String("health-interval", "", "time between health probes (default 10s)")
+		healthTimeout = fs.String("health-timeout", "", "health probe timeout (default 2s)")
+		healthyThreshold = fs.Int("healthy-threshold", 0, "successful probes before an upstream is healthy again (default 2)")
+		unhealthyThreshold = fs.Int("unhealthy-threshold", 0, "failed probes before an upstream is ejected (default 3)")
+		breakerFailures = fs.Int("breaker-failures", 0, "consecutive upstream failures that open the circuit breaker, enables the breaker")
+		breakerCooldown = fs.String("breaker-cooldown", "", "time an open breaker waits before half-opening (default 30s)")
+		breakerSuccesses = fs.Int("breaker-successes", 0, "successful probes that close a half-open breaker (default 2)")
+		retryAttempts = fs.Int("retry-attempts", 0, "total attempts per request including the first, enables retries")
+		retryOn = fs.String("retry-on", "", "comma separated retry conditions: connect-failure, reset (default both)")
+		retryStatus = fs.String("retry-status", "", "comma separated upstream status codes to retry, e.g. 502,503")
+		retryBackoff = fs.String("retry-backoff", "", "base backoff between retries (default 25ms)")
+		retryMaxBackoff = fs.String("retry-max-backoff", "", "maximum backoff between retries (default 250ms)")
+		retryNonIdempotent = fs.Bool("retry-non-idempotent", false, "also retry POST and PATCH requests without an Idempotency-Key")
+		retryBufferBytes = fs.Int64("retry-buffer-bytes", 0, "largest request body buffered for replay (default 1MiB)")
+		cacheEnabled = fs.Bool("cache", false, "cache upstream responses following their Cache-Control headers")
+		cacheTTL = fs.String("cache-ttl", "", "freshness of cacheable responses without caching headers, enables the cache")
+		cacheMaxObject = fs.Int64("cache-max-object-bytes", 0, "largest response stored in the cache (default 8MiB)")
+		rateRequests = fs.Int("rate-limit", 0, "requests per period allowed per client, enables rate limiting")
+		ratePeriod = fs.String("rate-period", "", "rate limit period (default 1s)")
+		rateBurst = fs.Int("rate-burst", 0, "requests a client may send at once (default --rate-limit)")
+		rateKey = fs.String("rate-key", "", "what identifies a client: ip, header, jwt_sub (default ip)")
+		rateHeader = fs.String("rate-header", "", "header identifying a client for --rate-key header")
+		mirrorURL = fs.String("mirror", "", "URL receiving a copy of the record's traffic, enables mirroring")
+		mirrorPercent = fs.Float64("mirror-percent", 0, "percentage of requests to mirror (default 100)")
+		mirrorBodyBytes = fs.Int64("mirror-body-bytes", 0, "largest request body copied to the mirror (default 64KiB)")
+		canaryTo = fs.String("canary", "", "canary target URL, enables canary routing")
+		canaryWeight = fs.Int("canary-weight", 0, "percentage of clients sent to the canary")
+		canaryCookieName = fs.String("canary-sticky-cookie", "", "cookie keeping clients on their side of the split (default prx_canary)")
+		fs.Var(&canaryHeaders, "canary-header", "send requests with header NAME[=VALUE] to the canary (repeatable)")
+		fs.Var(&canaryCookies, "canary-cookie", "send requests with cookie NAME[=VALUE] to the canary (repeatable)")
+		fs.Var(&canaryQueries, "canary-query", "send requests with query parameter NAME[=VALUE] to the canary (repeatable)")
+		fs.Var(&corsOrigins, "cors-origin", "allowed CORS origin, exact, * or a pattern such as https://*.example.com (repeatable)")
+		corsMethods = fs.String("cors-methods", "", "comma separated methods allowed by CORS (default GET,HEAD,POST)")
+		corsHeaders = fs.String("cors-headers", "", "comma separated request headers allowed by CORS (default any)")
+		corsExpose = fs.String("cors-expose-headers", "", "comma separated response headers exposed to scripts")
+		corsCredentials = fs.Bool("cors-credentials", false, "allow credentialed CORS requests")
+		corsMaxAge = fs.Int("cors-max-age", 0, "seconds browsers may cache a preflight answer")
+		connectTimeout = fs.String("connect-timeout", "", "time to connect to an upstream, e.g. 3s (0s for none)")
+		responseHeaderTimeout = fs.String("response-header-timeout", "", "time to wait for upstream response headers (0s for none)")
+		requestTimeout = fs.String("timeout", "", "time the whole upstream exchange may take (0s for none)")
+		idleTimeout = fs.String("idle-timeout", "", "time idle upstream connections are kept")
+		maxBodyBytes = fs.Int64("max-body-bytes", 0, "largest request body accepted, larger ones get 413")
+		maxResponseHeaderBytes = fs.Int64("max-response-header-bytes", 0, "largest upstream response header accepted")
+		upstreamCASecret = fs.String("upstream-ca-secret", "", "Secret whose ca.crt verifies https upstreams instead of the system roots")
+		upstreamCertSecret = fs.String("upstream-cert-secret", "", "kubernetes.io/tls Secret presented as client certificate to upstreams")
+		upstreamServerName = fs.String("upstream-server-name", "", "SNI and verified name used towards https upstreams")
+		upstreamInsecure = fs.Bool("upstream-insecure-skip-verify", false, "do not verify upstream certificates (insecure)")
+		maintenance = fs.Bool("maintenance", false, "answer every request with the maintenance page")
+		maintenanceRetryAfter = fs.String("maintenance-retry-after", "", "Retry-After sent in maintenance mode (default 5m)")
+		maintenanceMessage = fs.String("maintenance-message", "", "message shown on the maintenance page")
+		errorPages = fs.String("error-pages", "", "ConfigMap holding error page templates (default PRX_ERROR_PAGES)")
+		fs.Var(&allowCIDRs, "allow-cidr", "only admit clients in CIDR or IP (repeatable)")
+		fs.Var(&denyCIDRs, "deny-cidr", "reject clients in CIDR or IP (repeatable)")
+		authType = fs.String("auth", "", "edge authentication: basic, jwt, forward")
+		authRealm = fs.String("auth-realm", "", "realm sent in authentication challenges (default the host)")
+		authSecret = fs.String("auth-secret", "", "Secret holding htpasswd data under the auth key, for basic auth")
+		authJWKS = fs.String("auth-jwks-url", "", "JWKS URL validating bearer tokens, for jwt auth")
+		authIssuer = fs.String("auth-issuer", "", "required token issuer, for jwt auth")
+		authAudience = fs.String("auth-audience", "", "required token audience, for jwt auth")
+		fs.Var(&authClaims, "auth-claim", "require claim NAME=VALUE, for jwt auth (repeatable)")
+		fs.Var(&authClaimHeaders, "auth-claim-header", "pass claim CLAIM upstream as HEADER=CLAIM, for jwt auth (repeatable)")
+		authURL = fs.String("auth-url", "", "auth service URL, for forward auth")
+		fs.Var(&authHeaders, "auth-header", "header copied from the auth service answer upstream, for forward auth (repeatable)")
+		fs.Var(&reqSet, "request-header-set", "set a request header NAME=VALUE (repeatable)")
+		fs.Var(&reqAdd, "request-header-add", "add a request header NAME=VALUE (repeatable)")
+		fs.Var(&reqRemove, "request-header-remove", "remove a request header NAME (repeatable)")
+		fs.Var(&resSet, "response-header-set", "set a response header NAME=VALUE (repeatable)")
+		fs.Var(&resAdd, "response-header-add", "add a response header NAME=VALUE (repeatable)")
+		fs.Var(&resRemove, "response-header-remove", "remove a response header NAME (repeatable)")
 		fs.Parse(args[1:])
 	case "delete":
 		fs := flag.NewFlagSet(subcmd, flag.ExitOnError)
@@ -48,6 +173,42 @@ func Run(args []string) {
 		addr = fs.String("addr", os.Getenv("PROXY_HOST"), "gRPC server address")
 		token = fs.String("token", os.Getenv("PROXY_TOKEN"), "JWT bearer token")
 		fs.Parse(args[1:])
+	case "cache":
+		if len(args) < 2 || args[1] != "purge" {
+			PrintHelp()
+			os.Exit(1)
+		}
+		fs := flag.NewFlagSet("cache purge", flag.ExitOnError)
+		addr = fs.String("addr", os.Getenv("PROXY_HOST"), "gRPC server address")
+		token = fs.String("token", os.Getenv("PROXY_TOKEN"), "JWT bearer token")
+		from = fs.String("from", "", "source host")
+		purgePath = fs.String("path", "", "only purge paths starting with this prefix")
+		fs.Parse(args[2:])
+	case "canary":
+		if len(args) < 2 || (args[1] != "promote" && args[1] != "abort") {
+			PrintHelp()
+			os.Exit(1)
+		}
+		fs := flag.NewFlagSet("canary "+args[1], flag.ExitOnError)
+		addr = fs.String("addr", os.Getenv("PROXY_HOST"), "gRPC server address")
+		token = fs.String("token", os.Getenv("PROXY_TOKEN"), "JWT bearer token")
+		from = fs.String("from", "", "source host")
+		canaryAction = &args[1]
+		fs.Parse(args[2:])
+	case "maintenance":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			PrintHelp()
+			os.Exit(1)
+		}
+		fs := flag.NewFlagSet("maintenance "+args[1], flag.ExitOnError)
+		addr = fs.String("addr", os.Getenv("PROXY_HOST"), "gRPC server address")
+		token = fs.String("token", os.Getenv("PROXY_TOKEN"), "JWT bearer token")
+		from = fs.String("from", "", "source host")
+		maintenanceRetryAfter = fs.String("retry-after", "", "Retry-After sent in maintenance mode (default 5m)")
+		maintenanceMessage = fs.String("message", "", "message shown on the maintenance page")
+		enabled := args[1] == "on"
+		maintenance = &enabled
+		fs.Parse(args[2:])
 	case "help":
 		PrintHelp()
 		os.Exit(1)
@@ -57,23 +218,24 @@ func Run(args []string) {
 	}
 
 	var missing []string
-	if (subcmd == "add" || subcmd == "update") && (*from == "" || *to == "" || *certPath == "" || *keyPath == "" || *token == "") {
+	if (subcmd == "add" || subcmd == "update") && (*from == "" || (*to == "" && len(upstreams) == 0) || (*certPath == "") != (*keyPath == "") || *token == "") {
 		if *from == "" {
 			missing = append(missing, "from")
 		}
-		if *to == "" {
-			missing = append(missing, "to")
+		if *to == "" && len(upstreams) == 0 {
+			missing = append(missing, "to or upstream")
 		}
-		if *certPath == "" {
+		// Without both the certificate is issued through ACME.
+		if *certPath == "" && *keyPath != "" {
 			missing = append(missing, "cert")
 		}
-		if *keyPath == "" {
+		if *keyPath == "" && *certPath != "" {
 			missing = append(missing, "key")
 		}
 		if *token == "" {
 			missing = append(missing, "token")
 		}
-	} else if subcmd == "delete" && (*from == "" || *token == "") {
+	} else if (subcmd == "delete" || subcmd == "cache" || subcmd == "canary" || subcmd == "maintenance") && (*from == "" || *token == "") {
 		if *from == "" {
 			missing = append(missing, "from")
 		}
@@ -117,13 +279,189 @@ func Run(args []string) {
 
 	switch strings.ToLower(subcmd) {
 	case "add", "update":
-		certBytes, _ := os.ReadFile(*certPath)
-		keyBytes, _ := os.ReadFile(*keyPath)
+		rules, err := parseRoutes(routes)
+		if err != nil {
+			log.Fatal("Invalid route flag:", "err", err)
+		}
+		backends, err := parseUpstreams(upstreams)
+		if err != nil {
+			log.Fatal("Invalid upstream flag:", "err", err)
+		}
+		var cert, key string
+		if *certPath != "" {
+			certBytes, err := os.ReadFile(*certPath)
+			if err != nil {
+				log.Fatal("Invalid cert flag:", "err", err)
+			}
+			keyBytes, err := os.ReadFile(*keyPath)
+			if err != nil {
+				log.Fatal("Invalid key flag:", "err", err)
+			}
+			cert = base64.StdEncoding.EncodeToString(certBytes)
+			key = base64.StdEncoding.EncodeToString(keyBytes)
+		}
 		req := &pb.ProxyRequest{
-			From: *from,
-			To:   *to,
-			Cert: base64.StdEncoding.EncodeToString(certBytes),
-			Key:  base64.StdEncoding.EncodeToString(keyBytes),
+			From:          *from,
+			To:            *to,
+			Cert:          cert,
+			Key:           key,
+			Routes:        rules,
+			Upstreams:     backends,
+			Policy:        *policy,
+			HashHeader:    *hashHeader,
+			Mode:          *mode,
+			Protocol:      *protocol,
+			ListenPort:    int32(*listenPort),
+			ProxyProtocol: *proxyProtocol,
+		}
+		if *affinity != "" {
+			req.Affinity = &pb.SessionAffinity{
+				Type:      *affinity,
+				Cookie:    *affinityCookie,
+				CookieTtl: *affinityTTL,
+				Header:    *affinityHeader,
+			}
+		}
+		if *healthPath != "" {
+			req.HealthCheck = &pb.HealthCheck{
+				Path:               *healthPath,
+				Interval:           *healthInterval,
+				Timeout:            *healthTimeout,
+				HealthyThreshold:   int32(*healthyThreshold),
+				UnhealthyThreshold: int32(*unhealthyThreshold),
+			}
+		}
+		if *breakerFailures > 0 || *breakerCooldown != "" {
+			req.CircuitBreaker = &pb.CircuitBreaker{
+				ConsecutiveFailures: int32(*breakerFailures),
+				Cooldown:            *breakerCooldown,
+				SuccessThreshold:    int32(*breakerSuccesses),
+			}
+		}
+		if *retryAttempts > 0 {
+			codes, err := parseStatusCodes(*retryStatus)
+			if err != nil {
+				log.Fatal("Invalid retry-status flag:", "err", err)
+			}
+			req.Retry = &pb.RetryPolicy{
+				Attempts:           int32(*retryAttempts),
+				RetryOn:            splitList(*retryOn),
+				StatusCodes:        codes,
+				BaseBackoff:        *retryBackoff,
+				MaxBackoff:         *retryMaxBackoff,
+				RetryNonIdempotent: *retryNonIdempotent,
+				MaxBufferBytes:     *retryBufferBytes,
+			}
+		}
+		if *cacheEnabled || *cacheTTL != "" || *cacheMaxObject > 0 {
+			req.Cache = &pb.CachePolicy{
+				DefaultTtl:     *cacheTTL,
+				MaxObjectBytes: *cacheMaxObject,
+			}
+		}
+		if *rateRequests > 0 {
+			req.RateLimit = &pb.RateLimit{
+				Requests: int32(*rateRequests),
+				Period:   *ratePeriod,
+				Burst:    int32(*rateBurst),
+				Key:      *rateKey,
+				Header:   *rateHeader,
+			}
+		}
+		if *mirrorURL != "" {
+			req.Mirror = &pb.MirrorPolicy{
+				Url:          *mirrorURL,
+				Percent:      *mirrorPercent,
+				MaxBodyBytes: *mirrorBodyBytes,
+			}
+		}
+		if *canaryTo != "" {
+			req.Canary = &pb.Canary{
+				To:           *canaryTo,
+				Weight:       int32(*canaryWeight),
+				StickyCookie: *canaryCookieName,
+			}
+			req.Canary.Matches, err = parseCanaryMatches(canaryHeaders, canaryCookies, canaryQueries)
+			if err != nil {
+				log.Fatal("Invalid canary flag:", "err", err)
+			}
+		}
+		if len(corsOrigins) > 0 {
+			req.Cors = &pb.CORS{
+				AllowOrigins:     corsOrigins,
+				AllowMethods:     splitList(*corsMethods),
+				AllowHeaders:     splitList(*corsHeaders),
+				ExposeHeaders:    splitList(*corsExpose),
+				AllowCredentials: *corsCredentials,
+				MaxAge:           int32(*corsMaxAge),
+			}
+		}
+		if *connectTimeout != "" || *responseHeaderTimeout != "" || *requestTimeout != "" || *idleTimeout != "" ||
+			*maxBodyBytes != 0 || *maxResponseHeaderBytes != 0 {
+			req.Limits = &pb.Limits{
+				ConnectTimeout:         *connectTimeout,
+				ResponseHeaderTimeout:  *responseHeaderTimeout,
+				Timeout:                *requestTimeout,
+				IdleTimeout:            *idleTimeout,
+				MaxRequestBodyBytes:    *maxBodyBytes,
+				MaxResponseHeaderBytes: *maxResponseHeaderBytes,
+			}
+		}
+		if *upstreamCASecret != "" || *upstreamCertSecret != "" || *upstreamServerName != "" || *upstreamInsecure {
+			req.UpstreamTls = &pb.UpstreamTLS{
+				CaSecret:           *upstreamCASecret,
+				CertSecret:         *upstreamCertSecret,
+				ServerName:         *upstreamServerName,
+				InsecureSkipVerify: *upstreamInsecure,
+			}
+		}
+		if *maintenance || *maintenanceRetryAfter != "" || *maintenanceMessage != "" {
+			req.Maintenance = &pb.Maintenance{
+				Enabled:    *maintenance,
+				RetryAfter: *maintenanceRetryAfter,
+				Message:    *maintenanceMessage,
+			}
+		}
+		req.ErrorPages = *errorPages
+		if len(allowCIDRs) > 0 || len(denyCIDRs) > 0 {
+			req.IpAccess = &pb.IPAccess{Allow: allowCIDRs, Deny: denyCIDRs}
+		}
+		if *authType != "" {
+			req.Auth = &pb.EdgeAuth{
+				Type:        *authType,
+				Realm:       *authRealm,
+				Secret:      *authSecret,
+				JwksUrl:     *authJWKS,
+				Issuer:      *authIssuer,
+				Audience:    *authAudience,
+				Url:         *authURL,
+				AuthHeaders: authHeaders,
+			}
+			if req.Auth.Claims, err = parseKeyValues("claim", authClaims); err != nil {
+				log.Fatal("Invalid auth flag:", "err", err)
+			}
+			if req.Auth.ClaimHeaders, err = parseKeyValues("claim header", authClaimHeaders); err != nil {
+				log.Fatal("Invalid auth flag:", "err", err)
+			}
+		}
+		if *mode == "redirect" {
+			req.Redirect = &pb.RedirectRule{
+				Status:        int32(*redirectStatus),
+				PreservePath:  *preservePath,
+				PreserveQuery: *preserveQuery,
+			}
+			req.Redirect.Rewrites, err = parseRewrites(rewrites)
+			if err != nil {
+				log.Fatal("Invalid rewrite flag:", "err", err)
+			}
+		}
+		req.RequestHeaders, err = parseHeaderRules(reqSet, reqAdd, reqRemove)
+		if err != nil {
+			log.Fatal("Invalid request header flag:", "err", err)
+		}
+		req.ResponseHeaders, err = parseHeaderRules(resSet, resAdd, resRemove)
+		if err != nil {
+			log.Fatal("Invalid response header flag:", "err", err)
 		}
 		var action string
 		if subcmd == "add" {
@@ -141,8 +479,16 @@ func Run(args []string) {
 		fmt.Println(infoStyle.Render(header))
 		fmt.Printf("%s  %s\n",
 			lipgloss.NewStyle().Bold(true).Render("FROM:"), *from)
-		fmt.Printf("%s  %s\n\n",
+		fmt.Printf("%s  %s\n",
 			lipgloss.NewStyle().Bold(true).Render("TO:"), *to)
+		for _, u := range backends {
+			fmt.Printf("%s  %s\n",
+				lipgloss.NewStyle().Bold(true).Render("UPSTREAM:"), formatUpstreams([]*pb.Upstream{u}))
+		}
+		for _, r := range rules {
+			fmt.Printf("%s  %s\n",
+				lipgloss.NewStyle().Bold(true).Render("ROUTE:"), formatRoutes([]*pb.PathRule{r}))
+		}
 		fmt.Println("")
 
 	case "delete":
@@ -157,6 +503,58 @@ func Run(args []string) {
 			lipgloss.NewStyle().Bold(true).Render("FROM:"), *from)
 		fmt.Println("")
 
+	case "cache":
+		resp, err := client.PurgeCache(ctx, &pb.PurgeCacheRequest{From: *from, Path: *purgePath})
+		if err != nil {
+			log.Fatal("Purge cache failed:", "err", err)
+		}
+		fmt.Println("")
+		fmt.Println(successStyle.Render("Purged cached responses:"))
+		fmt.Printf("%s  %s\n",
+			lipgloss.NewStyle().Bold(true).Render("FROM:"), *from)
+		if *purgePath != "" {
+			fmt.Printf("%s  %s\n",
+				lipgloss.NewStyle().Bold(true).Render("PATH:"), *purgePath)
+		}
+		fmt.Printf("%s  %d\n",
+			lipgloss.NewStyle().Bold(true).Render("PURGED:"), resp.Purged)
+		fmt.Println("")
+
+	case "canary":
+		_, err = client.Canary(ctx, &pb.CanaryRequest{From: *from, Action: *canaryAction})
+		if err != nil {
+			log.Fatal("Canary "+*canaryAction+" failed:", "err", err)
+		}
+		fmt.Println("")
+		if *canaryAction == "promote" {
+			fmt.Println(successStyle.Render("Promoted canary:"))
+		} else {
+			fmt.Println(successStyle.Render("Aborted canary:"))
+		}
+		fmt.Printf("%s  %s\n",
+			lipgloss.NewStyle().Bold(true).Render("FROM:"), *from)
+		fmt.Println("")
+
+	case "maintenance":
+		_, err = client.Maintenance(ctx, &pb.MaintenanceRequest{
+			From:       *from,
+			Enabled:    *maintenance,
+			RetryAfter: *maintenanceRetryAfter,
+			Message:    *maintenanceMessage,
+		})
+		if err != nil {
+			log.Fatal("Maintenance "+args[1]+" failed:", "err", err)
+		}
+		fmt.Println("")
+		if *maintenance {
+			fmt.Println(successStyle.Render("Enabled maintenance mode:"))
+		} else {
+			fmt.Println(successStyle.Render("Disabled maintenance mode:"))
+		}
+		fmt.Printf("%s  %s\n",
+			lipgloss.NewStyle().Bold(true).Render("FROM:"), *from)
+		fmt.Println("")
+
 	case "list":
 		resp, err := client.List(ctx, &pb.ListRequest{})
 		if err != nil {
@@ -165,38 +563,11 @@ func Run(args []string) {
 		if len(resp.Records) < 1 {
 			log.Info("No records found")
 		} else {
-			maxFrom, maxTo := len("FROM"), len("TO")
-			for _, r := range resp.Records {
-				if len(r.From) > maxFrom {
-					maxFrom = len(r.From)
-				}
-				if len(r.To) > maxTo {
-					maxTo = len(r.To)
-				}
-			}
-
-			headerStyle := lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("63"))
-			cellStyle := lipgloss.NewStyle().
-				PaddingRight(1)
-			fmt.Println("")
-			fmt.Printf("%s  %s\n",
-				headerStyle.Render(fmt.Sprintf("%-*s", maxFrom, "FROM")),
-				headerStyle.Render(fmt.Sprintf("%-*s", maxTo, "TO")),
-			)
-
-			sepFrom := strings.Repeat("─", maxFrom)
-			sepTo := strings.Repeat("─", maxTo)
-			fmt.Printf("%s  %s\n", sepFrom, sepTo)
-
+			rows := make([][]string, 0, len(resp.Records))
 			for _, r := range resp.Records {
-				fmt.Printf("%s  %s\n",
-					cellStyle.Render(fmt.Sprintf("%-*s", maxFrom, r.From)),
-					cellStyle.Render(fmt.Sprintf("%-*s", maxTo, r.To)),
-				)
+				rows = append(rows, []string{r.From, r.To, formatUpstreams(r.Upstreams), r.Policy, formatRoutes(r.Routes), formatHealth(r.Health, r.Breakers), formatMirror(r.Mirror, r.Mirrored), formatCertificate(r.Certificate)})
 			}
-			fmt.Println("")
+			printTable([]string{"FROM", "TO", "UPSTREAMS", "POLICY", "ROUTES", "HEALTH", "MIRROR", "CERTIFICATE"}, rows)
 		}
 	}
 
@@ -204,3 +575,45 @@ func Run(args []string) {
 		log.Fatal(err)
 	}
 }
+
+func printTable(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("63"))
+	cellStyle := lipgloss.NewStyle().
+		PaddingRight(1)
+
+	render := func(style lipgloss.Style, cells []string) {
+		out := make([]string, len(cells))
+		for i, cell := range cells {
+			out[i] = style.Render(fmt.Sprintf("%-*s", widths[i], cell))
+		}
+		fmt.Println(strings.Join(out, "  "))
+	}
+
+	fmt.Println("")
+	render(headerStyle, headers)
+
+	seps := make([]string, len(widths))
+	for i, w := range widths {
+		seps[i] = strings.Repeat("─", w)
+	}
+	fmt.Println(strings.Join(seps, "  "))
+
+	for _, row := range rows {
+		render(cellStyle, row)
+	}
+	fmt.Println("")
+}
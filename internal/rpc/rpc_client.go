@@ -24,7 +24,7 @@ func StartClient() {
 		RunAuthUI()
 		os.Exit(0)
 
-	case "add", "update", "delete", "list":
+	case "add", "update", "delete", "list", "cache", "canary", "maintenance":
 		Run(os.Args[1:])
 		os.Exit(0)
 
@@ -0,0 +1,239 @@
+package rpc
+
+import (
+	"fmt"
+	"prx/internal/pb"
+	"strconv"
+	"strings"
+)
+
+// stringList is a flag.Value that collects every occurrence of a repeated flag.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseRoutes turns --route values of the form PREFIX=URL[,strip] into path rules.
+func parseRoutes(values []string) ([]*pb.PathRule, error) {
+	var rules []*pb.PathRule
+	for _, v := range values {
+		prefix, target, ok := strings.Cut(v, "=")
+		if !ok || prefix == "" || target == "" {
+			return nil, fmt.Errorf("invalid route %q, expected PREFIX=URL[,strip]", v)
+		}
+
+		rule := &pb.PathRule{Prefix: prefix, To: target}
+		if t, opt, ok := strings.Cut(target, ","); ok {
+			if opt != "strip" {
+				return nil, fmt.Errorf("invalid route option %q in %q", opt, v)
+			}
+			rule.To = t
+			rule.StripPrefix = true
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func formatRoutes(rules []*pb.PathRule) string {
+	var parts []string
+	for _, r := range rules {
+		part := r.Prefix + "=" + r.To
+		if r.StripPrefix {
+			part += ",strip"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseUpstreams turns --upstream values of the form URL[,WEIGHT] into upstreams.
+func parseUpstreams(values []string) ([]*pb.Upstream, error) {
+	var upstreams []*pb.Upstream
+	for _, v := range values {
+		u := &pb.Upstream{Url: v, Weight: 1}
+		if target, weight, ok := strings.Cut(v, ","); ok {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w < 1 {
+				return nil, fmt.Errorf("invalid upstream weight %q in %q", weight, v)
+			}
+			u.Url = target
+			u.Weight = int32(w)
+		}
+		if u.Url == "" {
+			return nil, fmt.Errorf("invalid upstream %q, expected URL[,WEIGHT]", v)
+		}
+		upstreams = append(upstreams, u)
+	}
+	return upstreams, nil
+}
+
+func formatUpstreams(upstreams []*pb.Upstream) string {
+	var parts []string
+	for _, u := range upstreams {
+		parts = append(parts, fmt.Sprintf("%s,%d", u.Url, u.Weight))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatHealth renders health check results followed by every circuit
+// breaker that is not closed.
+func formatHealth(status []*pb.UpstreamHealth, breakers []*pb.BreakerStatus) string {
+	var parts []string
+	for _, h := range status {
+		parts = append(parts, h.Url+"="+h.State)
+	}
+	for _, b := range breakers {
+		if b.State != "closed" {
+			parts = append(parts, b.Url+"=breaker_"+b.State)
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatMirror(policy *pb.MirrorPolicy, status *pb.MirrorStatus) string {
+	if policy == nil {
+		return "-"
+	}
+	if status == nil {
+		return policy.Url
+	}
+	return fmt.Sprintf("%s ok=%d failed=%d dropped=%d", policy.Url, status.Succeeded, status.Failed, status.Dropped)
+}
+
+// formatCertificate renders the state of a certificate issued through ACME.
+func formatCertificate(status *pb.CertificateStatus) string {
+	switch {
+	case status == nil:
+		return "-"
+	case status.State == "valid":
+		return "valid until " + status.NotAfter
+	case status.LastError != "":
+		return status.State + ": " + status.LastError
+	}
+	return status.State
+}
+
+// splitList splits a comma separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var res []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// parseStatusCodes turns a comma separated list such as "502,503" into codes.
+func parseStatusCodes(value string) ([]int32, error) {
+	var codes []int32
+	for _, v := range splitList(value) {
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", v)
+		}
+		codes = append(codes, int32(code))
+	}
+	return codes, nil
+}
+
+// parseHeaderRules builds header rules from NAME=VALUE set and add flags and
+// NAME remove flags. It returns nil when no flag was given.
+func parseHeaderRules(set, add, remove []string) (*pb.HeaderRules, error) {
+	if len(set) == 0 && len(add) == 0 && len(remove) == 0 {
+		return nil, nil
+	}
+	rules := &pb.HeaderRules{Remove: remove}
+	var err error
+	if rules.Set, err = parseHeaderValues(set); err != nil {
+		return nil, err
+	}
+	if rules.Add, err = parseHeaderValues(add); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func parseHeaderValues(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q, expected NAME=VALUE", v)
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+// parseKeyValues turns NAME=VALUE flag values into a map. It returns nil
+// when no flag was given.
+func parseKeyValues(kind string, values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected NAME=VALUE", kind, v)
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+// parseRewrites turns --rewrite values of the form REGEX=REPLACEMENT into
+// redirect rewrites. The regex ends at the first "=".
+func parseRewrites(values []string) ([]*pb.RegexRewrite, error) {
+	var res []*pb.RegexRewrite
+	for _, v := range values {
+		match, replacement, ok := strings.Cut(v, "=")
+		if !ok || match == "" {
+			return nil, fmt.Errorf("invalid rewrite %q, expected REGEX=REPLACEMENT", v)
+		}
+		res = append(res, &pb.RegexRewrite{Match: match, Replacement: replacement})
+	}
+	return res, nil
+}
+
+// parseCanaryMatches turns --canary-header, --canary-cookie and --canary-query
+// values of the form NAME[=VALUE] into canary matches.
+func parseCanaryMatches(headers, cookies, queries []string) ([]*pb.CanaryMatch, error) {
+	var res []*pb.CanaryMatch
+	for _, v := range headers {
+		name, value, _ := strings.Cut(v, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid canary header %q, expected NAME[=VALUE]", v)
+		}
+		res = append(res, &pb.CanaryMatch{Header: name, Value: value})
+	}
+	for _, v := range cookies {
+		name, value, _ := strings.Cut(v, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid canary cookie %q, expected NAME[=VALUE]", v)
+		}
+		res = append(res, &pb.CanaryMatch{Cookie: name, Value: value})
+	}
+	for _, v := range queries {
+		name, value, _ := strings.Cut(v, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid canary query %q, expected NAME[=VALUE]", v)
+		}
+		res = append(res, &pb.CanaryMatch{Query: name, Value: value})
+	}
+	return res, nil
+}
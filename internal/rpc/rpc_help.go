@@ -34,12 +34,37 @@ func PrintHelp() {
 		fmt.Sprintf("  %s\t%s", cmdStyle.Render("update"), "Update an existing redirect via gRPC"),
 		fmt.Sprintf("  %s\t%s", cmdStyle.Render("delete"), "Delete a redirect via gRPC"),
 		fmt.Sprintf("  %s\t%s", cmdStyle.Render("list"), "List all redirects via gRPC"),
+		fmt.Sprintf("  %s\t%s", cmdStyle.Render("cache purge"), "Purge cached responses of a host via gRPC"),
+		fmt.Sprintf("  %s\t%s", cmdStyle.Render("canary promote|abort"), "Promote or abort the canary of a record via gRPC"),
+		fmt.Sprintf("  %s\t%s", cmdStyle.Render("maintenance on|off"), "Switch maintenance mode of a record via gRPC"),
 		fmt.Sprintf("  %s\t%s", cmdStyle.Render("help"), "Show this help"),
 		"",
 		descStyle.Render("Example:"),
 		"  prx secret",
 		"  prx auth",
 		"  prx add --addr proxy:50051 --token $JWT --from example.com --to http://1.2.3.4 --cert /path/to.crt --key /path/to.key",
+		"  prx add --addr proxy:50051 --token $JWT --from example.com --to http://1.2.3.4   # certificate issued through ACME",
+		"  prx add ... --mode redirect --to https://new.example.com --redirect-status 301 --preserve-path",
+		"  prx update ... --mirror http://new-backend.svc.cluster.local --mirror-percent 10",
+		"  prx update ... --route /api=http://api:8080,strip --route /static=http://cdn",
+		"  prx update ... --upstream http://a:8080 --upstream http://b:8080 --affinity cookie --affinity-ttl 1h",
+		"  prx update ... --retry-attempts 3 --retry-status 502,503",
+		"  prx update ... --to http://greeter.svc.cluster.local:50051 --protocol grpc",
+		"  prx add ... --from mqtt.example.com --to mosquitto.svc.cluster.local:8883 --protocol tls",
+		"  prx add ... --from postgres --to postgres.svc.cluster.local:5432 --protocol tcp --listen-port 5432",
+		"  prx update ... --from postgres --proxy-protocol v2",
+		"  prx update ... --connect-timeout 3s --timeout 30s --max-body-bytes 10485760",
+		"  prx update ... --rate-limit 100 --rate-period 1m --rate-key jwt_sub",
+		"  prx update ... --request-header-set X-Tenant=acme --response-header-remove Server",
+		"  prx cache purge --addr proxy:50051 --token $JWT --from example.com --path /static",
+		"  prx update ... --cors-origin https://*.example.com --cors-methods GET,POST,PUT --cors-credentials",
+		"  prx update ... --allow-cidr 10.8.0.0/16 --allow-cidr 203.0.113.7 --deny-cidr 10.8.66.0/24",
+		"  prx update ... --auth jwt --auth-jwks-url https://idp/.well-known/jwks.json --auth-claim groups=admins",
+		"  prx update ... --canary http://v2:8080 --canary-weight 10 --canary-header X-Canary=always",
+		"  prx canary promote --addr proxy:50051 --token $JWT --from example.com",
+		"  prx update ... --to https://api.internal:8443 --upstream-ca-secret internal-ca --upstream-cert-secret prx-client",
+		"  prx update ... --error-pages prx-error-pages",
+		"  prx maintenance on --addr proxy:50051 --token $JWT --from example.com --retry-after 30m --message \"Back soon\"",
 		"",
 		descStyle.Render("Version:"),
 		"  " + ClientVersion,
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"prx/internal/models"
+	"prx/internal/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	// DefaultACMERenewBefore is how long before expiry certificates are renewed.
+	DefaultACMERenewBefore = 30 * 24 * time.Hour
+	// DefaultACMEAccountSecret holds the ACME account key.
+	DefaultACMEAccountSecret = "prx-acme-account"
+	// ACMEChallengePath is where HTTP-01 challenges are answered.
+	ACMEChallengePath = "/.well-known/acme-challenge/"
+)
+
+const (
+	acmeAccountKey     = "account.key"
+	acmeIssuerLabel    = "issued-by"
+	acmeIssuer         = "prx-acme"
+	acmeChallengeLabel = "acme-challenge"
+	acmeCheckEvery     = time.Minute
+	acmeMaxBackoff     = time.Hour
+	acmeIssueTimeout   = 5 * time.Minute
+	// acmeChallengePoll is how often the challenges of other replicas are
+	// listed, and how long a failed lookup of a host is not repeated.
+	acmeChallengePoll = 5 * time.Second
+	acmeLookupTimeout = 5 * time.Second
+)
+
+type acmeHost struct {
+	state     string
+	notAfter  time.Time
+	lastError string
+	next      time.Time
+	failures  int
+}
+
+type issuedCert struct {
+	cert, key []byte
+	leaf      *x509.Certificate
+}
+
+// ACME obtains and renews the certificates of records that have none
+// uploaded, answering HTTP-01 challenges on the plain HTTP listener.
+// Certificates are stored in the <host>-tls Secret of the record, labelled
+// issued-by=prx-acme so uploaded certificates are never replaced. Pending
+// challenges are also written to Secrets, which every replica lists on a
+// short interval so any of them can answer the CA.
+type ACME struct {
+	directory     string
+	email         string
+	accountSecret string
+	caSecret      string
+	renewBefore   time.Duration
+	secrets       SecretStore
+	log           *log.Logger
+	onIssued      func()
+
+	// client is only used by the Run goroutine.
+	client *acme.Client
+
+	mu         sync.Mutex
+	hosts      map[string]*acmeHost
+	issued     map[string]issuedCert
+	challenges map[string]string
+	wake       chan struct{}
+
+	// shared holds the challenges listed from Secrets by token, ordering the
+	// hosts they are for and misses when a lookup of a host last failed.
+	shared   map[string]string
+	ordering map[string]bool
+	misses   map[string]time.Time
+}
+
+// NewACMEFromEnv reads the directory URL from PRX_ACME_DIRECTORY (Let's
+// Encrypt by default), the account contact from PRX_ACME_EMAIL, the Secret
+// holding the account key from PRX_ACME_ACCOUNT_SECRET, a Secret with the
+// ca.crt of a private ACME server from PRX_ACME_CA_SECRET and the renewal
+// window from PRX_ACME_RENEW_BEFORE. onIssued runs after a certificate was
+// stored.
+func NewACMEFromEnv(logger *log.Logger, secrets SecretStore, onIssued func()) *ACME {
+	directory := os.Getenv("PRX_ACME_DIRECTORY")
+	if directory == "" {
+		directory = acme.LetsEncryptURL
+	}
+	account := os.Getenv("PRX_ACME_ACCOUNT_SECRET")
+	if account == "" {
+		account = DefaultACMEAccountSecret
+	}
+	m := NewACME(logger, secrets, directory, os.Getenv("PRX_ACME_EMAIL"), account, envDuration("PRX_ACME_RENEW_BEFORE", DefaultACMERenewBefore))
+	m.caSecret = os.Getenv("PRX_ACME_CA_SECRET")
+	m.onIssued = onIssued
+	return m
+}
+
+func NewACME(logger *log.Logger, secrets SecretStore, directory, email, accountSecret string, renewBefore time.Duration) *ACME {
+	return &ACME{
+		directory:     directory,
+		email:         email,
+		accountSecret: accountSecret,
+		renewBefore:   renewBefore,
+		secrets:       secrets,
+		log:           logger,
+		onIssued:      func() {},
+		hosts:         make(map[string]*acmeHost),
+		issued:        make(map[string]issuedCert),
+		challenges:    make(map[string]string),
+		wake:          make(chan struct{}, 1),
+		shared:        make(map[string]string),
+		ordering:      make(map[string]bool),
+		misses:        make(map[string]time.Time),
+	}
+}
+
+// Manage checks the certificate of host right away and keeps renewing it.
+// Regex hosts have no certificate of their own and are ignored.
+func (m *ACME) Manage(host string) {
+	if utils.IsRegexHost(host) {
+		return
+	}
+	m.mu.Lock()
+	entry, ok := m.hosts[host]
+	if !ok {
+		entry = &acmeHost{state: models.CertificatePending}
+		m.hosts[host] = entry
+	}
+	entry.next = time.Time{}
+	m.mu.Unlock()
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Forget stops renewing the certificate of host. An issued certificate is
+// kept in memory, so a record that is updated gets it back without being
+// issued again.
+func (m *ACME) Forget(host string) {
+	m.mu.Lock()
+	delete(m.hosts, host)
+	m.mu.Unlock()
+}
+
+// Status reports the certificate of host, or nil when it is not issued
+// through ACME.
+func (m *ACME) Status(host string) *models.CertificateStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.hosts[host]
+	if !ok {
+		return nil
+	}
+	status := &models.CertificateStatus{State: entry.state, LastError: entry.lastError}
+	if !entry.notAfter.IsZero() {
+		status.NotAfter = entry.notAfter.Format(time.RFC3339)
+	}
+	if !entry.next.IsZero() {
+		status.NextAttempt = entry.next.Format(time.RFC3339)
+	}
+	return status
+}
+
+// ServeChallenge answers an HTTP-01 challenge request. It returns false for
+// every other request, and for unknown tokens so an upstream can answer
+// challenges of its own. Requests are served from memory; only a host with
+// an order in progress is looked up in Secrets, at most once per poll.
+func (m *ACME) ServeChallenge(w http.ResponseWriter, req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.URL.Path, ACMEChallengePath)
+	if !ok || token == "" {
+		return false
+	}
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	keyAuth, ok, lookup := m.challenge(host, token)
+	// The order of another replica may have published this token after the
+	// challenges were last listed.
+	if !ok && lookup {
+		keyAuth, ok = m.lookupChallenge(host, token)
+	}
+	if !ok {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(keyAuth))
+	return true
+}
+
+func (m *ACME) challenge(host, token string) (keyAuth string, ok, lookup bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if keyAuth, ok = m.challenges[token]; ok {
+		return keyAuth, true, false
+	}
+	if keyAuth, ok = m.shared[token]; ok {
+		return keyAuth, true, false
+	}
+	_, managed := m.hosts[host]
+	if !managed || !m.ordering[host] || time.Since(m.misses[host]) < acmeChallengePoll {
+		return "", false, false
+	}
+	m.misses[host] = time.Now()
+	return "", false, true
+}
+
+func (m *ACME) lookupChallenge(host, token string) (string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), acmeLookupTimeout)
+	defer cancel()
+
+	secret, err := m.secrets.Get(ctx, challengeSecret(token))
+	if err != nil {
+		m.log.Error("Failed to read acme challenge", "host", host, "err", err)
+		return "", false
+	}
+	if secret == nil || string(secret.Data["token"]) != token {
+		return "", false
+	}
+	keyAuth := string(secret.Data["keyAuthorization"])
+	m.mu.Lock()
+	m.shared[token] = keyAuth
+	m.mu.Unlock()
+	return keyAuth, true
+}
+
+// pollChallenges lists the challenges published by all replicas until ctx
+// is done.
+func (m *ACME) pollChallenges(ctx context.Context) {
+	ticker := time.NewTicker(acmeChallengePoll)
+	defer ticker.Stop()
+	for {
+		m.listChallenges(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *ACME) listChallenges(ctx context.Context) {
+	listCtx, cancel := context.WithTimeout(ctx, acmeLookupTimeout)
+	defer cancel()
+
+	secrets, err := m.secrets.List(listCtx, map[string]string{acmeIssuerLabel: acmeIssuer, acmeChallengeLabel: "true"})
+	if err != nil {
+		m.log.Error("Failed to list acme challenges", "err", err)
+		return
+	}
+	shared := make(map[string]string, len(secrets))
+	ordering := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		shared[string(secret.Data["token"])] = string(secret.Data["keyAuthorization"])
+		ordering[string(secret.Data["host"])] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shared = shared
+	m.ordering = ordering
+	for host, at := range m.misses {
+		if time.Since(at) >= acmeChallengePoll {
+			delete(m.misses, host)
+		}
+	}
+}
+
+// Run checks the managed certificates until ctx is done, issuing missing
+// ones and renewing those about to expire.
+func (m *ACME) Run(ctx context.Context) {
+	go m.pollChallenges(ctx)
+
+	ticker := time.NewTicker(acmeCheckEvery)
+	defer ticker.Stop()
+	for {
+		for _, host := range m.due() {
+			m.reconcile(ctx, host)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+func (m *ACME) due() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var hosts []string
+	for host, entry := range m.hosts {
+		if !entry.next.After(now) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func (m *ACME) reconcile(ctx context.Context, host string) {
+	name := ResourceName(host) + "-tls"
+	secret, err := m.secrets.Get(ctx, name)
+	if err != nil {
+		m.fail(host, err)
+		return
+	}
+	if secret != nil && secret.Labels[acmeIssuerLabel] != acmeIssuer {
+		m.log.Info("Record has an uploaded certificate, not using acme", "host", host)
+		m.Forget(host)
+		return
+	}
+
+	// A certificate stored by us or another replica that is still fresh.
+	if secret != nil {
+		if leaf, err := parseLeaf(secret.Data[UpstreamCertKey]); err == nil && leaf.VerifyHostname(host) == nil && time.Now().Before(m.renewAt(leaf)) {
+			m.valid(host, leaf)
+			return
+		}
+	}
+
+	// The Secret was deleted by an update of the record.
+	m.mu.Lock()
+	cached, ok := m.issued[host]
+	m.mu.Unlock()
+	if ok && time.Now().Before(m.renewAt(cached.leaf)) {
+		if err := m.store(name, cached); err != nil {
+			m.fail(host, err)
+			return
+		}
+		m.valid(host, cached.leaf)
+		m.onIssued()
+		return
+	}
+
+	if strings.HasPrefix(host, "*.") {
+		m.fail(host, errors.New("wildcard hosts need a DNS-01 challenge, upload a certificate instead"))
+		return
+	}
+
+	m.setState(host, models.CertificateIssuing)
+	m.log.Info("Issuing acme certificate", "host", host, "directory", m.directory)
+
+	issueCtx, cancel := context.WithTimeout(ctx, acmeIssueTimeout)
+	defer cancel()
+	issued, err := m.issue(issueCtx, host)
+	if err == nil {
+		err = m.store(name, issued)
+	}
+	if err != nil {
+		m.fail(host, err)
+		return
+	}
+
+	m.mu.Lock()
+	m.issued[host] = issued
+	m.mu.Unlock()
+	m.valid(host, issued.leaf)
+	m.log.Info("Issued acme certificate", "host", host, "expires", issued.leaf.NotAfter)
+	m.onIssued()
+}
+
+// issue orders a certificate for host and answers its HTTP-01 challenges.
+func (m *ACME) issue(ctx context.Context, host string) (issuedCert, error) {
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return issuedCert{}, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(host))
+	if err != nil {
+		return issuedCert{}, fmt.Errorf("order: %v", err)
+	}
+
+	var tokens []string
+	defer func() {
+		for _, token := range tokens {
+			m.unpublish(token)
+		}
+	}()
+
+	for _, url := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, url)
+		if err != nil {
+			return issuedCert{}, fmt.Errorf("authorization: %v", err)
+		}
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		var challenge *acme.Challenge
+		for _, c := range authz.Challenges {
+			if c.Type == "http-01" {
+				challenge = c
+				break
+			}
+		}
+		if challenge == nil {
+			return issuedCert{}, errors.New("the acme server offers no http-01 challenge")
+		}
+
+		keyAuth, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return issuedCert{}, err
+		}
+		m.publish(host, challenge.Token, keyAuth)
+		tokens = append(tokens, challenge.Token)
+
+		// Give the other replicas one poll to list the challenge, as the CA
+		// may validate it through any of them.
+		select {
+		case <-ctx.Done():
+			return issuedCert{}, ctx.Err()
+		case <-time.After(acmeChallengePoll + time.Second):
+		}
+
+		if _, err := client.Accept(ctx, challenge); err != nil {
+			return issuedCert{}, fmt.Errorf("accept challenge: %v", err)
+		}
+		if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+			return issuedCert{}, fmt.Errorf("challenge: %v", err)
+		}
+	}
+
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return issuedCert{}, fmt.Errorf("order: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return issuedCert{}, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: host},
+		DNSNames: []string{host},
+	}, key)
+	if err != nil {
+		return issuedCert{}, err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return issuedCert{}, fmt.Errorf("finalize: %v", err)
+	}
+	if len(chain) == 0 {
+		return issuedCert{}, errors.New("the acme server returned no certificate")
+	}
+
+	issued := issuedCert{}
+	for _, der := range chain {
+		issued.cert = append(issued.cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return issuedCert{}, err
+	}
+	issued.key = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if issued.leaf, err = x509.ParseCertificate(chain[0]); err != nil {
+		return issuedCert{}, err
+	}
+	return issued, nil
+}
+
+// acmeClient registers the account on first use, creating its key when the
+// account Secret does not exist yet.
+func (m *ACME) acmeClient(ctx context.Context) (*acme.Client, error) {
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	key, err := m.accountKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("account key: %v", err)
+	}
+	client := &acme.Client{Key: key, DirectoryURL: m.directory, UserAgent: "prx"}
+	if m.caSecret != "" {
+		if client.HTTPClient, err = m.caClient(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	account := &acme.Account{}
+	if m.email != "" {
+		account.Contact = []string{"mailto:" + m.email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("register account: %v", err)
+	}
+
+	m.client = client
+	return client, nil
+}
+
+func (m *ACME) accountKey(ctx context.Context) (crypto.Signer, error) {
+	secret, err := m.secrets.Get(ctx, m.accountSecret)
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil && len(secret.Data[acmeAccountKey]) > 0 {
+		block, _ := pem.Decode(secret.Data[acmeAccountKey])
+		if block == nil {
+			return nil, fmt.Errorf("secret %s holds no PEM key", m.accountSecret)
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	err = m.secrets.Apply(StoredSecret{
+		Name: m.accountSecret,
+		Data: map[string][]byte{acmeAccountKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})},
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("Created acme account key", "secret", m.accountSecret)
+	return key, nil
+}
+
+// caClient trusts the ca.crt of caSecret, for ACME servers such as Pebble
+// that use a private CA.
+func (m *ACME) caClient(ctx context.Context) (*http.Client, error) {
+	secret, err := m.secrets.Get(ctx, m.caSecret)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("secret %s not found", m.caSecret)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[UpstreamCAKey]) {
+		return nil, fmt.Errorf("secret %s holds no PEM certificates in %s", m.caSecret, UpstreamCAKey)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
+}
+
+func (m *ACME) store(name string, issued issuedCert) error {
+	return m.secrets.Apply(StoredSecret{
+		Name:   name,
+		TLS:    true,
+		Labels: map[string]string{acmeIssuerLabel: acmeIssuer},
+		Data: map[string][]byte{
+			UpstreamCertKey: issued.cert,
+			UpstreamKeyKey:  issued.key,
+		},
+	})
+}
+
+func (m *ACME) publish(host, token, keyAuth string) {
+	m.mu.Lock()
+	m.challenges[token] = keyAuth
+	m.mu.Unlock()
+
+	err := m.secrets.Apply(StoredSecret{
+		Name:   challengeSecret(token),
+		Labels: map[string]string{acmeIssuerLabel: acmeIssuer, acmeChallengeLabel: "true"},
+		Data: map[string][]byte{
+			"host":             []byte(host),
+			"token":            []byte(token),
+			"keyAuthorization": []byte(keyAuth),
+		},
+	})
+	if err != nil {
+		m.log.Warn("Failed to share acme challenge, only this replica can answer it", "err", err)
+	}
+}
+
+func (m *ACME) unpublish(token string) {
+	m.mu.Lock()
+	delete(m.challenges, token)
+	m.mu.Unlock()
+
+	if err := m.secrets.Delete(challengeSecret(token)); err != nil {
+		m.log.Warn("Failed to delete acme challenge", "err", err)
+	}
+}
+
+// renewAt is when a certificate is renewed: renewBefore ahead of its expiry,
+// but not before two thirds of its lifetime for short-lived certificates.
+func (m *ACME) renewAt(leaf *x509.Certificate) time.Time {
+	at := leaf.NotAfter.Add(-m.renewBefore)
+	if floor := leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) * 2 / 3); at.Before(floor) {
+		at = floor
+	}
+	return at
+}
+
+func (m *ACME) setState(host, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.hosts[host]; ok {
+		entry.state = state
+	}
+}
+
+func (m *ACME) valid(host string, leaf *x509.Certificate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.hosts[host]
+	if !ok {
+		return
+	}
+	entry.state = models.CertificateValid
+	entry.notAfter = leaf.NotAfter
+	entry.lastError = ""
+	entry.failures = 0
+	// Replicas renewing the same certificate are spread out, so the first
+	// one stores it before the others look.
+	entry.next = m.renewAt(leaf).Add(mathrand.N(10 * time.Minute))
+}
+
+// fail records err and retries with a backoff doubling up to an hour.
+func (m *ACME) fail(host string, err error) {
+	m.log.Error("Failed to obtain acme certificate", "host", host, "err", err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.hosts[host]
+	if !ok {
+		return
+	}
+	backoff := acmeMaxBackoff
+	if entry.failures < 6 {
+		backoff = min(acmeCheckEvery<<entry.failures, acmeMaxBackoff)
+	}
+	entry.failures++
+	entry.state = models.CertificateFailed
+	entry.lastError = err.Error()
+	entry.next = time.Now().Add(backoff)
+}
+
+// challengeSecret names the Secret of a challenge token, which may contain
+// characters not allowed in resource names.
+func challengeSecret(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "prx-acme-challenge-" + hex.EncodeToString(sum[:8])
+}
+
+func parseLeaf(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/url"
+	"prx/internal/models"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ringReplicas is the number of points each unit of weight gets on the
+// consistent hash ring.
+const ringReplicas = 160
+
+var (
+	ErrNoUpstream        = errors.New("no upstream available")
+	ErrNoHealthyUpstream = errors.New("no healthy upstream available")
+)
+
+// Backend is a single upstream target of a Balancer. ID is derived from the
+// URL, so it names the same upstream on every replica without revealing it.
+type Backend struct {
+	URL    *url.URL
+	Weight int
+	ID     string
+
+	active  atomic.Int64
+	current int // smooth weighted round robin state, guarded by Balancer.mu
+}
+
+// Acquire marks a request as in flight on the backend. Every call must be
+// paired with Release so least_conn can compare backends.
+func (b *Backend) Acquire() { b.active.Add(1) }
+
+// Release marks an in-flight request as finished.
+func (b *Backend) Release() { b.active.Add(-1) }
+
+// Active returns the number of in-flight requests.
+func (b *Backend) Active() int64 { return b.active.Load() }
+
+type ringPoint struct {
+	hash    uint64
+	backend *Backend
+}
+
+// Balancer picks one of a record's upstreams per request according to the
+// record's policy.
+type Balancer struct {
+	policy   string
+	backends []*Backend
+	ring     []ringPoint
+	ringOnce sync.Once
+	mu       sync.Mutex
+}
+
+// NewBalancer builds a balancer for the given upstreams. An empty policy
+// means round robin.
+func NewBalancer(upstreams []models.Upstream, policy string) (*Balancer, error) {
+	if len(upstreams) == 0 {
+		return nil, ErrNoUpstream
+	}
+	if policy == "" {
+		policy = models.PolicyRoundRobin
+	}
+
+	b := &Balancer{policy: policy}
+	for _, u := range upstreams {
+		parsed, err := url.Parse(u.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream url %s: %v", u.URL, err)
+		}
+		weight := u.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		id := strconv.FormatUint(hashKey(parsed.String()), 16)
+		b.backends = append(b.backends, &Backend{URL: parsed, Weight: weight, ID: id})
+	}
+
+	if policy == models.PolicyIPHash || policy == models.PolicyHeaderHash {
+		b.ringOnce.Do(b.buildRing)
+	}
+
+	return b, nil
+}
+
+// Backends returns the upstreams in declaration order.
+func (b *Balancer) Backends() []*Backend {
+	return b.backends
+}
+
+// Pick returns the backend for the next request, skipping backends for
+// which available returns false. hashKey is only used by the hash policies;
+// an empty key falls back to round robin.
+func (b *Balancer) Pick(hashKey string, available func(*Backend) bool) (*Backend, error) {
+	if len(b.backends) == 0 {
+		return nil, ErrNoUpstream
+	}
+	if available == nil {
+		available = func(*Backend) bool { return true }
+	}
+
+	var be *Backend
+	switch b.policy {
+	case models.PolicyWeightedRandom:
+		be = b.pickRandom(available)
+	case models.PolicyLeastConn:
+		be = b.pickLeastConn(available)
+	case models.PolicyIPHash, models.PolicyHeaderHash:
+		if hashKey != "" {
+			be = b.pickHash(hashKey, available)
+			break
+		}
+		be = b.pickRoundRobin(available)
+	default:
+		be = b.pickRoundRobin(available)
+	}
+
+	if be == nil {
+		return nil, ErrNoHealthyUpstream
+	}
+	return be, nil
+}
+
+// PickHash returns the backend of key on the consistent hash ring whatever
+// the policy, so the same key keeps landing on the same backend on every
+// replica while that backend is available.
+func (b *Balancer) PickHash(key string, available func(*Backend) bool) (*Backend, error) {
+	if len(b.backends) == 0 {
+		return nil, ErrNoUpstream
+	}
+	b.ringOnce.Do(b.buildRing)
+
+	be := b.pickHash(key, available)
+	if be == nil {
+		return nil, ErrNoHealthyUpstream
+	}
+	return be, nil
+}
+
+// Lookup returns the backend with the given ID, or nil.
+func (b *Balancer) Lookup(id string) *Backend {
+	for _, be := range b.backends {
+		if be.ID == id {
+			return be
+		}
+	}
+	return nil
+}
+
+// pickRoundRobin implements smooth weighted round robin so heavier backends
+// are interleaved with lighter ones instead of being hit in bursts.
+func (b *Balancer) pickRoundRobin(available func(*Backend) bool) *Backend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var best *Backend
+	total := 0
+	for _, be := range b.backends {
+		if !available(be) {
+			continue
+		}
+		be.current += be.Weight
+		total += be.Weight
+		if best == nil || be.current > best.current {
+			best = be
+		}
+	}
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+func (b *Balancer) pickRandom(available func(*Backend) bool) *Backend {
+	var candidates []*Backend
+	total := 0
+	for _, be := range b.backends {
+		if available(be) {
+			candidates = append(candidates, be)
+			total += be.Weight
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	n := rand.IntN(total)
+	for _, be := range candidates {
+		n -= be.Weight
+		if n < 0 {
+			return be
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// pickLeastConn chooses the backend with the fewest in-flight requests
+// relative to its weight.
+func (b *Balancer) pickLeastConn(available func(*Backend) bool) *Backend {
+	var best *Backend
+	var bestScore float64
+	for _, be := range b.backends {
+		if !available(be) {
+			continue
+		}
+		score := float64(be.Active()) / float64(be.Weight)
+		if best == nil || score < bestScore {
+			best, bestScore = be, score
+		}
+	}
+	return best
+}
+
+// pickHash walks the ring clockwise from the key's position so a key only
+// moves to another backend while its own backend is unavailable.
+func (b *Balancer) pickHash(key string, available func(*Backend) bool) *Backend {
+	h := hashKey(key)
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+	for i := 0; i < len(b.ring); i++ {
+		point := b.ring[(start+i)%len(b.ring)]
+		if available(point.backend) {
+			return point.backend
+		}
+	}
+	return nil
+}
+
+func (b *Balancer) buildRing() {
+	for _, be := range b.backends {
+		for i := 0; i < be.Weight*ringReplicas; i++ {
+			b.ring = append(b.ring, ringPoint{
+				hash:    hashKey(be.URL.String() + "#" + strconv.Itoa(i)),
+				backend: be,
+			})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
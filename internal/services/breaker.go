@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"prx/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults used when a record leaves a setting empty.
+const (
+	DefaultBreakerFailures  = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+	DefaultBreakerSuccesses = 2
+)
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// CircuitOpenError is returned when every candidate upstream of a request has
+// an open breaker. RetryAfter is the time until the first one half-opens.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds for the
+// Retry-After header.
+func (e *CircuitOpenError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// Breaker is the circuit breaker of a single upstream. It opens after a run
+// of consecutive failures, lets a single probe request through once the
+// cooldown has passed and closes again after enough successful probes.
+type Breaker struct {
+	failuresToOpen   int
+	cooldown         time.Duration
+	successesToClose int
+	mu               sync.Mutex
+	state            string
+	failures         int
+	successes        int
+	openedAt         time.Time
+	probeInFlight    bool
+}
+
+// Available reports whether the breaker would let a request through without
+// claiming the half-open probe slot.
+func (b *Breaker) Available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return time.Since(b.openedAt) >= b.cooldown
+	case BreakerHalfOpen:
+		return !b.probeInFlight
+	}
+	return true
+}
+
+// Allow claims permission to send a request. Every allowed request must be
+// followed by Success, Failure or Abandon.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.successes = 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+	}
+	return true
+}
+
+// Success records a request that reached the upstream and got a non 5xx answer.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == BreakerHalfOpen {
+		b.probeInFlight = false
+		b.successes++
+		if b.successes >= b.successesToClose {
+			b.state = BreakerClosed
+		}
+	}
+}
+
+// Failure records a 5xx answer, a connection error or a timeout.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probeInFlight = false
+		b.trip()
+	case BreakerClosed:
+		b.failures++
+		if b.failures >= b.failuresToOpen {
+			b.trip()
+		}
+	}
+}
+
+// Abandon releases an allowed request without judging the upstream, e.g.
+// when the client went away first.
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probeInFlight = false
+	}
+}
+
+// RetryAfter returns how long the breaker stays open.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return 0
+	}
+	return max(b.cooldown-time.Since(b.openedAt), 0)
+}
+
+func (b *Breaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+	b.successes = 0
+}
+
+func (b *Breaker) status(upstream string) models.BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := models.BreakerStatus{
+		URL:      upstream,
+		State:    b.state,
+		Failures: b.failures,
+	}
+	if b.state == BreakerOpen {
+		status.OpenedAt = b.openedAt.Format(time.RFC3339)
+	}
+	return status
+}
+
+// BreakerGroup holds the breakers of every upstream of one record.
+type BreakerGroup struct {
+	cfg      models.CircuitBreaker
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewBreakerGroup(cfg models.CircuitBreaker) *BreakerGroup {
+	return &BreakerGroup{
+		cfg:      cfg,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get returns the breaker of an upstream, creating a closed one on first use.
+func (g *BreakerGroup) Get(upstream *url.URL) *Breaker {
+	key := upstream.String()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[key]
+	if !ok {
+		b = &Breaker{
+			failuresToOpen:   intOr(g.cfg.ConsecutiveFailures, DefaultBreakerFailures),
+			cooldown:         durationOr(g.cfg.Cooldown, DefaultBreakerCooldown),
+			successesToClose: intOr(g.cfg.SuccessThreshold, DefaultBreakerSuccesses),
+			state:            BreakerClosed,
+		}
+		g.breakers[key] = b
+	}
+	return b
+}
+
+// Status returns the state of every breaker that has seen traffic.
+func (g *BreakerGroup) Status() []models.BreakerStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	res := make([]models.BreakerStatus, 0, len(g.breakers))
+	for u, b := range g.breakers {
+		res = append(res, b.status(u))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].URL < res[j].URL })
+	return res
+}
+
+// RetryAfter returns the shortest remaining cooldown of the open breakers.
+func (g *BreakerGroup) RetryAfter() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var shortest time.Duration
+	for _, b := range g.breakers {
+		if d := b.RetryAfter(); d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return shortest
+}
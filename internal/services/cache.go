@@ -0,0 +1,459 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/gob"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Cache defaults used when the PRX_CACHE_* variables are not set.
+const (
+	DefaultCacheMaxBytes       = 256 << 20
+	DefaultCacheDiskMaxBytes   = 1 << 30
+	DefaultCacheMaxObjectBytes = 8 << 20
+)
+
+// CacheEntry is a stored upstream response together with the times needed
+// to compute its age.
+type CacheEntry struct {
+	Status       int
+	Header       http.Header
+	Body         []byte
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+func (e *CacheEntry) size() int64 {
+	n := int64(len(e.Body)) + 256
+	for k, vs := range e.Header {
+		for _, v := range vs {
+			n += int64(len(k) + len(v))
+		}
+	}
+	return n
+}
+
+type cacheItem struct {
+	primary string
+	key     string
+	size    int64
+	entry   *CacheEntry // nil while the entry lives on disk
+	file    string
+}
+
+// cachePrimary tracks the Vary headers of a primary key and the keys of its
+// stored variants.
+type cachePrimary struct {
+	vary []string
+	keys map[string]struct{}
+}
+
+// CacheFlight is an upstream fetch that concurrent misses of the same key
+// wait on instead of sending their own request.
+type CacheFlight struct {
+	done chan struct{}
+}
+
+// Wait blocks until the leader has landed the flight or ctx is done.
+func (f *CacheFlight) Wait(ctx context.Context) {
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+	}
+}
+
+// Cache is the response store shared by every record with caching enabled.
+// Entries are kept in an in-memory LRU; when a spillover directory is set,
+// entries evicted from memory move to disk, which is an LRU of its own.
+// Responses with a Vary header are stored per variant of the primary key.
+type Cache struct {
+	log          *log.Logger
+	maxBytes     int64
+	dir          string
+	diskMaxBytes int64
+
+	mu        sync.Mutex
+	mem       *list.List
+	memBytes  int64
+	disk      *list.List
+	diskBytes int64
+	items     map[string]*list.Element
+	primaries map[string]*cachePrimary
+	flights   map[string]*CacheFlight
+	pending   map[string]*cacheItem // moving between memory and disk
+}
+
+// NewCacheFromEnv reads PRX_CACHE_MAX_BYTES, PRX_CACHE_DIR and
+// PRX_CACHE_DISK_MAX_BYTES. Disk spillover is off unless PRX_CACHE_DIR is set.
+func NewCacheFromEnv(logger *log.Logger) *Cache {
+	return NewCache(logger,
+		int64(envInt("PRX_CACHE_MAX_BYTES", DefaultCacheMaxBytes)),
+		os.Getenv("PRX_CACHE_DIR"),
+		int64(envInt("PRX_CACHE_DISK_MAX_BYTES", DefaultCacheDiskMaxBytes)),
+	)
+}
+
+func NewCache(logger *log.Logger, maxBytes int64, dir string, diskMaxBytes int64) *Cache {
+	c := &Cache{
+		log:          logger,
+		maxBytes:     maxBytes,
+		dir:          dir,
+		diskMaxBytes: diskMaxBytes,
+		mem:          list.New(),
+		disk:         list.New(),
+		items:        make(map[string]*list.Element),
+		primaries:    make(map[string]*cachePrimary),
+		flights:      make(map[string]*CacheFlight),
+		pending:      make(map[string]*cacheItem),
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			logger.Error("Cache spillover disabled", "dir", dir, "err", err)
+			c.dir = ""
+		}
+		// Entries left over from a previous run are not indexed, drop them.
+		stale, _ := filepath.Glob(filepath.Join(dir, "*.entry"))
+		for _, f := range stale {
+			os.Remove(f)
+		}
+	}
+	return c
+}
+
+// CacheKey returns the primary cache key of a request.
+func CacheKey(host, requestURI string) string {
+	return strings.ToLower(host) + " " + requestURI
+}
+
+// Get returns the entry stored for the request's variant of primary, or nil.
+// Entries on disk are read without holding the lock.
+func (c *Cache) Get(primary string, reqHeader http.Header) *CacheEntry {
+	c.mu.Lock()
+
+	p, ok := c.primaries[primary]
+	if !ok {
+		c.mu.Unlock()
+		return nil
+	}
+	key := variantKey(primary, p.vary, reqHeader)
+
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*cacheItem)
+		if entry := item.entry; entry != nil {
+			c.mem.MoveToFront(el)
+			c.mu.Unlock()
+			return entry
+		}
+		c.disk.Remove(el)
+		c.diskBytes -= item.size
+		delete(c.items, key)
+		c.pending[key] = item
+		c.mu.Unlock()
+		return c.load(item)
+	}
+
+	// An entry still being spilled is taken back into memory.
+	if item, ok := c.pending[key]; ok && item.entry != nil {
+		entry := item.entry
+		delete(c.pending, key)
+		spills := c.addMem(item.primary, key, entry)
+		c.mu.Unlock()
+		c.spill(spills)
+		return entry
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// Put stores entry for the request's variant of primary. Responses with
+// "Vary: *" and entries larger than the memory cap are not stored.
+func (c *Cache) Put(primary string, reqHeader http.Header, entry *CacheEntry) {
+	names := varyNames(entry.Header)
+	if len(names) == 1 && names[0] == "*" {
+		return
+	}
+	if entry.size() > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+
+	// A new set of Vary headers invalidates every variant stored so far.
+	if p, ok := c.primaries[primary]; ok && strings.Join(p.vary, ",") != strings.Join(names, ",") {
+		c.removePrimary(primary)
+	}
+	key := variantKey(primary, names, reqHeader)
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	delete(c.pending, key)
+	if _, ok := c.primaries[primary]; !ok {
+		c.primaries[primary] = &cachePrimary{vary: names, keys: make(map[string]struct{})}
+	}
+	spills := c.addMem(primary, key, entry)
+	c.mu.Unlock()
+
+	c.spill(spills)
+}
+
+// Remove drops every variant of primary.
+func (c *Cache) Remove(primary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removePrimary(primary)
+}
+
+// Purge drops every entry of host whose path starts with path. An empty
+// path purges the whole host. It returns the number of entries removed.
+func (c *Cache) Purge(host, path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := CacheKey(host, path)
+	n := 0
+	for primary := range c.primaries {
+		if strings.HasPrefix(primary, prefix) {
+			n += c.removePrimary(primary)
+		}
+	}
+	return n
+}
+
+// PurgeMatching drops every entry whose host is accepted by match and whose
+// path starts with path. It returns the number of entries removed.
+func (c *Cache) PurgeMatching(match func(host string) bool, path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for primary := range c.primaries {
+		host, uri, _ := strings.Cut(primary, " ")
+		if strings.HasPrefix(uri, path) && match(host) {
+			n += c.removePrimary(primary)
+		}
+	}
+	return n
+}
+
+// Lead registers a fetch of primary. The first caller becomes the leader and
+// must call Land when done; everyone else gets the running flight to wait on.
+func (c *Cache) Lead(primary string) (*CacheFlight, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if f, ok := c.flights[primary]; ok {
+		return f, false
+	}
+	f := &CacheFlight{done: make(chan struct{})}
+	c.flights[primary] = f
+	return f, true
+}
+
+// Land ends the flight of primary and releases its waiters.
+func (c *Cache) Land(primary string, f *CacheFlight) {
+	c.mu.Lock()
+	delete(c.flights, primary)
+	c.mu.Unlock()
+
+	close(f.done)
+}
+
+// addMem stores an entry in memory and returns the entries evicted to make
+// room that go to disk. Callers hold c.mu and pass them to spill once they
+// released it.
+func (c *Cache) addMem(primary, key string, entry *CacheEntry) []*cacheItem {
+	item := &cacheItem{primary: primary, key: key, size: entry.size(), entry: entry}
+	c.items[key] = c.mem.PushFront(item)
+	c.memBytes += item.size
+	if p, ok := c.primaries[primary]; ok {
+		p.keys[key] = struct{}{}
+	}
+
+	var spills []*cacheItem
+	for c.memBytes > c.maxBytes {
+		el := c.mem.Back()
+		evicted := el.Value.(*cacheItem)
+		c.mem.Remove(el)
+		c.memBytes -= evicted.size
+		delete(c.items, evicted.key)
+		if c.dir == "" || evicted.size > c.diskMaxBytes {
+			c.forget(evicted)
+			continue
+		}
+		c.pending[evicted.key] = evicted
+		spills = append(spills, evicted)
+	}
+	return spills
+}
+
+// spill writes entries evicted from memory to disk without holding c.mu and
+// adds them to the disk LRU unless they were replaced or purged meanwhile.
+func (c *Cache) spill(items []*cacheItem) {
+	for _, item := range items {
+		file, err := c.writeDisk(item.entry)
+
+		c.mu.Lock()
+		if c.pending[item.key] != item {
+			c.mu.Unlock()
+			if err == nil {
+				os.Remove(file)
+			}
+			continue
+		}
+		delete(c.pending, item.key)
+		if err != nil {
+			c.forget(item)
+			c.mu.Unlock()
+			c.log.Warn("Cache spillover failed", "key", item.key, "err", err)
+			continue
+		}
+		item.entry, item.file = nil, file
+		c.items[item.key] = c.disk.PushFront(item)
+		c.diskBytes += item.size
+		for c.diskBytes > c.diskMaxBytes {
+			c.removeElement(c.disk.Back())
+		}
+		c.mu.Unlock()
+	}
+}
+
+// load reads an entry taken off the disk LRU without holding c.mu and moves
+// it back to memory unless it was replaced or purged meanwhile.
+func (c *Cache) load(item *cacheItem) *CacheEntry {
+	entry, err := c.readDisk(item.file)
+	os.Remove(item.file)
+
+	c.mu.Lock()
+	if c.pending[item.key] != item {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.pending, item.key)
+	if err != nil {
+		c.forget(item)
+		c.mu.Unlock()
+		c.log.Warn("Dropping unreadable cache entry", "key", item.key, "err", err)
+		return nil
+	}
+	spills := c.addMem(item.primary, item.key, entry)
+	c.mu.Unlock()
+
+	c.spill(spills)
+	return entry
+}
+
+func (c *Cache) removePrimary(primary string) int {
+	p, ok := c.primaries[primary]
+	if !ok {
+		return 0
+	}
+	n := 0
+	for key := range p.keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+			n++
+		} else if _, ok := c.pending[key]; ok {
+			delete(c.pending, key)
+			n++
+		}
+	}
+	delete(c.primaries, primary)
+	return n
+}
+
+func (c *Cache) removeElement(el *list.Element) {
+	item := el.Value.(*cacheItem)
+	delete(c.items, item.key)
+	c.forget(item)
+	if item.entry != nil {
+		c.mem.Remove(el)
+		c.memBytes -= item.size
+		return
+	}
+	c.disk.Remove(el)
+	c.diskBytes -= item.size
+	os.Remove(item.file)
+}
+
+// forget drops an item from its primary key, and the primary key once its
+// last variant is gone.
+func (c *Cache) forget(item *cacheItem) {
+	p, ok := c.primaries[item.primary]
+	if !ok {
+		return
+	}
+	delete(p.keys, item.key)
+	if len(p.keys) == 0 {
+		delete(c.primaries, item.primary)
+	}
+}
+
+func (c *Cache) writeDisk(entry *CacheEntry) (string, error) {
+	f, err := os.CreateTemp(c.dir, "*.entry")
+	if err != nil {
+		return "", err
+	}
+	if err := gob.NewEncoder(f).Encode(entry); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func (c *Cache) readDisk(file string) (*CacheEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entry CacheEntry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// varyNames returns the sorted, canonical header names listed in Vary.
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "*" {
+				return []string{"*"}
+			} else if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func variantKey(primary string, names []string, reqHeader http.Header) string {
+	if len(names) == 0 {
+		return primary
+	}
+	var b strings.Builder
+	b.WriteString(primary)
+	for _, name := range names {
+		b.WriteString("\x00" + name + "=" + strings.Join(reqHeader.Values(name), ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// DefaultCertRefresh is how often the TLS Secrets are listed for changes.
+const DefaultCertRefresh = 30 * time.Second
+
+// TLSSecretLister returns the TLS Secrets certificates are served from.
+type TLSSecretLister func() ([]TLSSecret, error)
+
+type storedCert struct {
+	version string
+	cert    *tls.Certificate
+	leaf    *x509.Certificate
+}
+
+// CertStore serves the certificates of the TLS Secrets prx manages, picked
+// by the server name a client asks for. Secrets are listed every interval
+// and only the ones whose resource version changed are parsed again.
+type CertStore struct {
+	list     TLSSecretLister
+	log      *log.Logger
+	interval time.Duration
+	fallback string
+
+	// refreshing serializes Refresh, so a slow listing cannot replace the
+	// index of a newer one.
+	refreshing sync.Mutex
+
+	mu      sync.RWMutex
+	secrets map[string]storedCert
+	names   map[string]*tls.Certificate
+}
+
+// NewCertStoreFromEnv reads the refresh interval from PRX_TLS_REFRESH and the
+// Secret served to clients without a matching certificate from
+// PRX_TLS_DEFAULT_SECRET.
+func NewCertStoreFromEnv(logger *log.Logger, list TLSSecretLister) *CertStore {
+	return NewCertStore(logger, list, envDuration("PRX_TLS_REFRESH", DefaultCertRefresh), os.Getenv("PRX_TLS_DEFAULT_SECRET"))
+}
+
+func NewCertStore(logger *log.Logger, list TLSSecretLister, interval time.Duration, fallback string) *CertStore {
+	return &CertStore{
+		list:     list,
+		log:      logger,
+		interval: interval,
+		fallback: fallback,
+		secrets:  make(map[string]storedCert),
+		names:    make(map[string]*tls.Certificate),
+	}
+}
+
+// Run refreshes the certificates until ctx is done.
+func (s *CertStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Refresh(); err != nil {
+			s.log.Error("Failed to refresh tls certificates", "err", err)
+		}
+	}
+}
+
+// Refresh lists the TLS Secrets and indexes their certificates by the names
+// they are valid for. When several certificates cover a name, the one that
+// expires last wins. Secrets that cannot be parsed are skipped.
+func (s *CertStore) Refresh() error {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+
+	list, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	old := s.secrets
+	s.mu.RUnlock()
+
+	secrets := make(map[string]storedCert, len(list))
+	for _, secret := range list {
+		if stored, ok := old[secret.Name]; ok && stored.version == secret.ResourceVersion {
+			secrets[secret.Name] = stored
+			continue
+		}
+		stored, err := parseStoredCert(secret)
+		if err != nil {
+			s.log.Error("Skipping invalid tls secret", "secret", secret.Name, "err", err)
+			continue
+		}
+		s.log.Info("Loaded tls certificate", "secret", secret.Name, "names", stored.leaf.DNSNames, "expires", stored.leaf.NotAfter)
+		secrets[secret.Name] = stored
+	}
+
+	names := make(map[string]*tls.Certificate)
+	expiry := make(map[string]time.Time)
+	for _, stored := range secrets {
+		for _, name := range certNames(stored.leaf) {
+			if cur, ok := expiry[name]; !ok || stored.leaf.NotAfter.After(cur) {
+				names[name] = stored.cert
+				expiry[name] = stored.leaf.NotAfter
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.secrets = secrets
+	s.names = names
+	s.mu.Unlock()
+	return nil
+}
+
+// GetCertificate picks the certificate for the server name of a handshake:
+// an exact name first, then a wildcard for its parent domain, then the
+// default Secret.
+func (s *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cert, ok := s.names[name]; ok {
+		return cert, nil
+	}
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		if cert, ok := s.names["*."+parent]; ok {
+			return cert, nil
+		}
+	}
+	if stored, ok := s.secrets[s.fallback]; ok {
+		return stored.cert, nil
+	}
+	return nil, fmt.Errorf("no certificate for %q", hello.ServerName)
+}
+
+func parseStoredCert(secret TLSSecret) (storedCert, error) {
+	cert, err := tls.X509KeyPair(secret.Cert, secret.Key)
+	if err != nil {
+		return storedCert{}, err
+	}
+	leaf := cert.Leaf
+	if leaf == nil {
+		if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return storedCert{}, err
+		}
+	}
+	return storedCert{version: secret.ResourceVersion, cert: &cert, leaf: leaf}, nil
+}
+
+// certNames returns the DNS names a certificate is valid for, falling back
+// to its common name for certificates without SANs.
+func certNames(leaf *x509.Certificate) []string {
+	names := leaf.DNSNames
+	if len(names) == 0 && leaf.Subject.CommonName != "" {
+		names = []string{leaf.Subject.CommonName}
+	}
+	res := make([]string, 0, len(names))
+	for _, n := range names {
+		res = append(res, strings.ToLower(n))
+	}
+	return res
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Edge authentication defaults used when the PRX_AUTH_* variables are unset.
+const (
+	DefaultAuthCacheTTL = time.Minute
+	DefaultAuthTimeout  = 5 * time.Second
+)
+
+// HtpasswdKey is the key of the htpasswd data in a basic auth Secret, the
+// same key ingress-nginx uses.
+const HtpasswdKey = "auth"
+
+// ErrUnauthenticated is returned for missing, invalid or expired credentials.
+var ErrUnauthenticated = errors.New("invalid credentials")
+
+// SecretReader returns the data of the named Secret.
+type SecretReader func(name string) (map[string][]byte, error)
+
+type htpasswdEntry struct {
+	users   Htpasswd
+	fetched time.Time
+}
+
+// EdgeAuth checks the credentials of records with edge authentication. The
+// htpasswd Secrets and JWKS documents it reads are kept for ttl, so rotated
+// credentials and keys take effect without restarting prx.
+type EdgeAuth struct {
+	secrets SecretReader
+	client  *http.Client
+	forward *http.Client
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	htpasswd map[string]htpasswdEntry
+	jwks     map[string]*jwks
+}
+
+// NewEdgeAuthFromEnv reads PRX_AUTH_CACHE_TTL and PRX_AUTH_TIMEOUT.
+func NewEdgeAuthFromEnv(secrets SecretReader, transport http.RoundTripper) *EdgeAuth {
+	return NewEdgeAuth(secrets, transport,
+		envDuration("PRX_AUTH_CACHE_TTL", DefaultAuthCacheTTL),
+		envDuration("PRX_AUTH_TIMEOUT", DefaultAuthTimeout),
+	)
+}
+
+func NewEdgeAuth(secrets SecretReader, transport http.RoundTripper, ttl, timeout time.Duration) *EdgeAuth {
+	return &EdgeAuth{
+		secrets: secrets,
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+		forward: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		ttl:      ttl,
+		htpasswd: make(map[string]htpasswdEntry),
+		jwks:     make(map[string]*jwks),
+	}
+}
+
+// Forward sends a forward-auth subrequest. Redirects are not followed, so
+// a redirect to a login page reaches the client.
+func (e *EdgeAuth) Forward(req *http.Request) (*http.Response, error) {
+	return e.forward.Do(req)
+}
+
+// Basic checks a user and password against the htpasswd data of secret.
+// The error is ErrUnauthenticated when the credentials do not match.
+func (e *EdgeAuth) Basic(secret, user, password string) error {
+	users, err := e.htpasswdOf(secret)
+	if err != nil {
+		return err
+	}
+	if !users.Verify(user, password) {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+func (e *EdgeAuth) htpasswdOf(secret string) (Htpasswd, error) {
+	e.mu.Lock()
+	entry, ok := e.htpasswd[secret]
+	e.mu.Unlock()
+	if ok && time.Since(entry.fetched) < e.ttl {
+		return entry.users, nil
+	}
+
+	data, err := e.secrets(secret)
+	if err != nil {
+		if ok {
+			// Keep serving the last known users while the API is unreachable.
+			return entry.users, nil
+		}
+		return nil, err
+	}
+	users, err := ParseHtpasswd(data[HtpasswdKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %v", secret, err)
+	}
+
+	e.mu.Lock()
+	e.htpasswd[secret] = htpasswdEntry{users: users, fetched: time.Now()}
+	e.mu.Unlock()
+	return users, nil
+}
+
+// JWT verifies the signature of token against the keys published at
+// jwksURL and checks its expiry, issuer and audience. An empty issuer or
+// audience is not checked. Any failure is reported as ErrUnauthenticated
+// except for a JWKS that cannot be fetched.
+func (e *EdgeAuth) JWT(jwksURL, issuer, audience, token string) (jwt.MapClaims, error) {
+	var keyErr error
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		"RS256", "RS384", "RS512", "PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512", "EdDSA",
+	}))
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := e.jwksKey(jwksURL, kid)
+		keyErr = err
+		return key, err
+	})
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	if issuer != "" && !claims.VerifyIssuer(issuer, true) {
+		return nil, ErrUnauthenticated
+	}
+	if audience != "" && !claims.VerifyAudience(audience, true) {
+		return nil, ErrUnauthenticated
+	}
+	return claims, nil
+}
+
+// jwksKey returns the key with ID kid, refetching the set when it is older
+// than ttl or, at most every jwksMinRefresh, when it lacks the key.
+func (e *EdgeAuth) jwksKey(url, kid string) (any, error) {
+	e.mu.Lock()
+	set := e.jwks[url]
+	e.mu.Unlock()
+
+	if set != nil && time.Since(set.fetched) < e.ttl {
+		if key, ok := set.key(kid); ok {
+			return key, nil
+		}
+		if time.Since(set.fetched) < jwksMinRefresh {
+			return nil, ErrUnauthenticated
+		}
+	}
+
+	fresh, err := fetchJWKS(e.client, url)
+	if err != nil {
+		if set == nil {
+			return nil, err
+		}
+		// Keep validating against the last known keys.
+		fresh = set
+	} else {
+		e.mu.Lock()
+		e.jwks[url] = fresh
+		e.mu.Unlock()
+	}
+
+	key, ok := fresh.key(kid)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return key, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// DefaultErrorPagesTTL is how long error page templates are kept before
+// their ConfigMap is read again.
+const DefaultErrorPagesTTL = time.Minute
+
+// MaintenancePage is the template key tried first for maintenance answers.
+const MaintenancePage = "maintenance"
+
+// ConfigMapReader returns the data of the named ConfigMap.
+type ConfigMapReader func(name string) (map[string]string, error)
+
+// ErrorPageData is available to error page templates.
+type ErrorPageData struct {
+	Status     int
+	StatusText string
+	Host       string
+	Path       string
+	Message    string
+	RequestID  string
+	RetryAfter int
+}
+
+type pageTemplate interface {
+	Execute(w *bytes.Buffer, data any) error
+}
+
+type htmlPage struct{ t *htmltemplate.Template }
+
+func (p htmlPage) Execute(w *bytes.Buffer, data any) error { return p.t.Execute(w, data) }
+
+type textPage struct{ t *texttemplate.Template }
+
+func (p textPage) Execute(w *bytes.Buffer, data any) error { return p.t.Execute(w, data) }
+
+type pageSet struct {
+	pages   map[string]pageTemplate
+	fetched time.Time
+}
+
+// ErrorPages renders error answers from templates kept in ConfigMaps. A
+// ConfigMap holds templates under keys such as "502.html", "504.json",
+// "maintenance.html" or "default.html"; .html keys are rendered with
+// html/template and .json keys with text/template, where {{json .Message}}
+// quotes a value. Templates are kept for ttl, so edits to the ConfigMap
+// take effect without restarting prx.
+type ErrorPages struct {
+	configMaps ConfigMapReader
+	log        *log.Logger
+	ttl        time.Duration
+	global     string
+
+	mu   sync.Mutex
+	sets map[string]*pageSet
+}
+
+// NewErrorPagesFromEnv reads the global default ConfigMap from
+// PRX_ERROR_PAGES and the reload interval from PRX_ERROR_PAGES_TTL.
+func NewErrorPagesFromEnv(logger *log.Logger, configMaps ConfigMapReader) *ErrorPages {
+	return NewErrorPages(logger, configMaps, os.Getenv("PRX_ERROR_PAGES"),
+		envDuration("PRX_ERROR_PAGES_TTL", DefaultErrorPagesTTL))
+}
+
+func NewErrorPages(logger *log.Logger, configMaps ConfigMapReader, global string, ttl time.Duration) *ErrorPages {
+	return &ErrorPages{
+		configMaps: configMaps,
+		log:        logger,
+		ttl:        ttl,
+		global:     global,
+		sets:       make(map[string]*pageSet),
+	}
+}
+
+// Render renders the page for status from the record's ConfigMap, falling
+// back to the global one. key replaces the status as the first template
+// tried, e.g. MaintenancePage. Pages in the format the client prefers win,
+// which is JSON when accept asks for it without asking for HTML. ok is false
+// when neither ConfigMap has a page.
+func (p *ErrorPages) Render(configMap, key string, accept string, data ErrorPageData) (contentType string, body []byte, ok bool) {
+	exts := []string{"html", "json"}
+	if strings.Contains(accept, "json") && !strings.Contains(accept, "html") {
+		exts = []string{"json", "html"}
+	}
+	var keys []string
+	if key != "" {
+		keys = append(keys, key)
+	}
+	keys = append(keys, strconv.Itoa(data.Status), "default")
+
+	var sets []*pageSet
+	var names []string
+	for _, name := range []string{configMap, p.global} {
+		if name == "" {
+			continue
+		}
+		if set := p.setOf(name); set != nil {
+			sets = append(sets, set)
+			names = append(names, name)
+		}
+	}
+
+	for _, ext := range exts {
+		for i, set := range sets {
+			for _, k := range keys {
+				page, found := set.pages[k+"."+ext]
+				if !found {
+					continue
+				}
+				var buf bytes.Buffer
+				if err := page.Execute(&buf, data); err != nil {
+					p.log.Error("Failed to render error page", "configmap", names[i], "page", k+"."+ext, "err", err)
+					continue
+				}
+				if ext == "json" {
+					return "application/json", buf.Bytes(), true
+				}
+				return "text/html; charset=utf-8", buf.Bytes(), true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// setOf returns the templates of a ConfigMap, reading it again after ttl.
+// The last good templates are kept while the ConfigMap cannot be read.
+func (p *ErrorPages) setOf(name string) *pageSet {
+	p.mu.Lock()
+	set := p.sets[name]
+	p.mu.Unlock()
+	if set != nil && time.Since(set.fetched) < p.ttl {
+		return set
+	}
+
+	data, err := p.configMaps(name)
+	if err != nil {
+		p.log.Error("Failed to read error pages", "configmap", name, "err", err)
+		// Remember the failure so every error answer does not hit the API.
+		stale := &pageSet{fetched: time.Now()}
+		if set != nil {
+			stale.pages = set.pages
+		}
+		set = stale
+		p.mu.Lock()
+		p.sets[name] = set
+		p.mu.Unlock()
+		return set
+	}
+
+	fresh := &pageSet{pages: make(map[string]pageTemplate), fetched: time.Now()}
+	for key, src := range data {
+		page, err := parsePage(key, src)
+		if err != nil {
+			p.log.Error("Invalid error page template", "configmap", name, "page", key, "err", err)
+			continue
+		}
+		if page != nil {
+			fresh.pages[key] = page
+		}
+	}
+
+	p.mu.Lock()
+	p.sets[name] = fresh
+	p.mu.Unlock()
+	return fresh
+}
+
+func parsePage(key, src string) (pageTemplate, error) {
+	switch {
+	case strings.HasSuffix(key, ".html"):
+		t, err := htmltemplate.New(key).Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		return htmlPage{t}, nil
+	case strings.HasSuffix(key, ".json"):
+		t, err := texttemplate.New(key).Funcs(texttemplate.FuncMap{"json": jsonValue}).Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		return textPage{t}, nil
+	}
+	return nil, nil
+}
+
+func jsonValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("json: %v", err)
+	}
+	return string(b), nil
+}
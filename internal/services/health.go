@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"prx/internal/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Health check defaults used when a record leaves a setting empty.
+const (
+	DefaultHealthInterval     = 10 * time.Second
+	DefaultHealthTimeout      = 2 * time.Second
+	DefaultHealthyThreshold   = 2
+	DefaultUnhealthyThreshold = 3
+)
+
+// Health states reported for an upstream.
+const (
+	HealthUnknown   = "unknown"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+type upstreamHealth struct {
+	state       string
+	successes   int
+	failures    int
+	lastError   string
+	lastChecked time.Time
+}
+
+type healthWatch struct {
+	cancel    context.CancelFunc
+	client    *http.Client
+	upstreams map[string]*upstreamHealth
+}
+
+// HealthChecker probes the upstreams of every record that has a health check
+// configured and remembers which of them are fit to receive traffic.
+type HealthChecker struct {
+	client  *http.Client
+	log     *log.Logger
+	mu      sync.RWMutex
+	watches map[string]*healthWatch
+}
+
+func NewHealthChecker(log *log.Logger) *HealthChecker {
+	return &HealthChecker{
+		client: &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		log:     log,
+		watches: make(map[string]*healthWatch),
+	}
+}
+
+// Watch starts probing the upstreams of a record through transport,
+// replacing any previous watch for the same record. Upstreams start in the
+// unknown state and keep receiving traffic until they fail the unhealthy
+// threshold.
+func (h *HealthChecker) Watch(from string, upstreams []models.Upstream, cfg models.HealthCheck, transport http.RoundTripper) {
+	h.Unwatch(from)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watch := &healthWatch{
+		cancel: cancel,
+		client: &http.Client{
+			Transport:     transport,
+			CheckRedirect: h.client.CheckRedirect,
+		},
+		upstreams: make(map[string]*upstreamHealth, len(upstreams)),
+	}
+	for _, u := range upstreams {
+		// Key by the normalised form so lookups with a parsed url.URL match.
+		key := u.URL
+		if parsed, err := url.Parse(u.URL); err == nil {
+			key = parsed.String()
+		}
+		watch.upstreams[key] = &upstreamHealth{state: HealthUnknown}
+	}
+
+	h.mu.Lock()
+	h.watches[from] = watch
+	h.mu.Unlock()
+
+	h.log.Info("Health checks started", "from", from, "path", cfg.Path, "upstreams", len(upstreams))
+	go h.run(ctx, from, watch, cfg)
+}
+
+// Unwatch stops the health checks of a record and forgets its state.
+func (h *HealthChecker) Unwatch(from string) {
+	h.mu.Lock()
+	watch, ok := h.watches[from]
+	delete(h.watches, from)
+	h.mu.Unlock()
+
+	if ok {
+		watch.cancel()
+	}
+}
+
+// Healthy reports whether the upstream may receive traffic. Upstreams of
+// records without health checks are always healthy.
+func (h *HealthChecker) Healthy(from, upstream string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	watch, ok := h.watches[from]
+	if !ok {
+		return true
+	}
+	state, ok := watch.upstreams[upstream]
+	return !ok || state.state != HealthUnhealthy
+}
+
+// Status returns the health of every checked upstream of a record, or nil
+// when the record has no health checks.
+func (h *HealthChecker) Status(from string) []models.UpstreamHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	watch, ok := h.watches[from]
+	if !ok {
+		return nil
+	}
+
+	res := make([]models.UpstreamHealth, 0, len(watch.upstreams))
+	for u, state := range watch.upstreams {
+		status := models.UpstreamHealth{
+			URL:       u,
+			State:     state.state,
+			LastError: state.lastError,
+		}
+		if !state.lastChecked.IsZero() {
+			status.LastChecked = state.lastChecked.Format(time.RFC3339)
+		}
+		res = append(res, status)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].URL < res[j].URL })
+	return res
+}
+
+func (h *HealthChecker) run(ctx context.Context, from string, watch *healthWatch, cfg models.HealthCheck) {
+	interval := durationOr(cfg.Interval, DefaultHealthInterval)
+	timeout := durationOr(cfg.Timeout, DefaultHealthTimeout)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var wg sync.WaitGroup
+		for u, state := range watch.upstreams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := h.probe(ctx, watch.client, u, cfg.Path, timeout)
+				h.record(from, u, state, err, cfg)
+			}()
+		}
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HealthChecker) probe(ctx context.Context, client *http.Client, upstream, path string, timeout time.Duration) error {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return err
+	}
+	target.Path = path
+	target.RawQuery = ""
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "prx-health-check")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (h *HealthChecker) record(from, upstream string, state *upstreamHealth, err error, cfg models.HealthCheck) {
+	healthyThreshold := intOr(cfg.HealthyThreshold, DefaultHealthyThreshold)
+	unhealthyThreshold := intOr(cfg.UnhealthyThreshold, DefaultUnhealthyThreshold)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The watch may have been replaced while the probe was in flight.
+	if watch, ok := h.watches[from]; !ok || watch.upstreams[upstream] != state {
+		return
+	}
+
+	previous := state.state
+	state.lastChecked = time.Now()
+	if err != nil {
+		state.lastError = err.Error()
+		state.successes = 0
+		state.failures++
+		if state.failures >= unhealthyThreshold {
+			state.state = HealthUnhealthy
+		}
+	} else {
+		state.lastError = ""
+		state.failures = 0
+		state.successes++
+		if state.successes >= healthyThreshold || state.state == HealthUnknown {
+			state.state = HealthHealthy
+		}
+	}
+
+	if previous != state.state && state.state == HealthUnhealthy {
+		h.log.Warn("Upstream marked unhealthy", "from", from, "upstream", upstream, "err", state.lastError)
+	} else if previous == HealthUnhealthy && state.state == HealthHealthy {
+		h.log.Info("Upstream recovered", "from", from, "upstream", upstream)
+	}
+}
+
+func durationOr(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+func intOr(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
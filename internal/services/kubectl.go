@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"prx/internal/models"
+	"prx/internal/utils"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/log"
@@ -19,6 +21,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 )
 
 type Kube struct {
@@ -27,8 +30,9 @@ type Kube struct {
 }
 
 type ProxyMapping struct {
-	From string `yaml:"from"`
-	To   string `yaml:"to"`
+	From             string `yaml:"from"`
+	To               string `yaml:"to"`
+	models.ProxySpec `yaml:",inline"`
 }
 
 func NewKubeClient(log *log.Logger) (Kube, error) {
@@ -81,40 +85,30 @@ func (k Kube) AddNewProxy(anyBody any, namespace, name string) error {
 
 	body := anyBody.(models.AddNewProxy)
 
-	cert, err := base64.StdEncoding.DecodeString(body.Cert)
-	if err != nil {
-		return err
-	}
-	key, err := base64.StdEncoding.DecodeString(body.Key)
-	if err != nil {
-		return err
+	// An Ingress host cannot be a regular expression. Regex records are served
+	// through an Ingress that already routes their hosts to prx.
+	if utils.IsRegexHost(body.From) {
+		k.log.Info("Skipping ingress for regex record", "from", body.From)
+		return nil
 	}
 
-	// Create TLS secret with the provided certificate and key.
-	secretName := body.From + "-tls"
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: secretName,
-			Labels: map[string]string{
-				"managed-by": "prx",
-			},
-			Namespace: namespace,
-		},
-		Type: corev1.SecretTypeTLS,
-		Data: map[string][]byte{
-			"tls.crt": []byte(cert),
-			"tls.key": []byte(key),
-		},
+	// Layer 4 records reach prx on its own listeners, not through the
+	// ingress controller.
+	if body.Protocol == models.ProtocolTLS || body.Protocol == models.ProtocolTCP {
+		k.log.Info("Skipping ingress for layer 4 record", "from", body.From, "protocol", body.Protocol)
+		return nil
 	}
 
-	_, err = k.client.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
-	if err != nil {
+	secretName := ResourceName(body.From) + "-tls"
+	if body.Cert == "" && body.Key == "" {
+		// The ACME client stores the certificate in the Secret once issued.
+		k.log.Info("Skipping secret, the certificate is issued through ACME", "from", body.From)
+	} else if err := k.createTLSSecret(body, namespace, secretName); err != nil {
 		return err
 	}
 
-	k.log.Info("Created secret", "name", secretName, "from", body.From, "to", body.To)
 	ingressClassName := "nginx"
-	ingressName := body.From + "-ingress"
+	ingressName := ResourceName(body.From) + "-ingress"
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: ingressName,
@@ -160,7 +154,7 @@ func (k Kube) AddNewProxy(anyBody any, namespace, name string) error {
 		},
 	}
 
-	_, err = k.client.NetworkingV1().Ingresses(namespace).Create(context.Background(), ingress, metav1.CreateOptions{})
+	_, err := k.client.NetworkingV1().Ingresses(namespace).Create(context.Background(), ingress, metav1.CreateOptions{})
 	if err != nil {
 		return err
 	}
@@ -170,10 +164,63 @@ func (k Kube) AddNewProxy(anyBody any, namespace, name string) error {
 	return nil
 }
 
+// createTLSSecret stores the uploaded certificate and key of a record.
+func (k Kube) createTLSSecret(body models.AddNewProxy, namespace, secretName string) error {
+	cert, err := base64.StdEncoding.DecodeString(body.Cert)
+	if err != nil {
+		return err
+	}
+	key, err := base64.StdEncoding.DecodeString(body.Key)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretName,
+			Labels: map[string]string{
+				"managed-by": "prx",
+			},
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": []byte(cert),
+			"tls.key": []byte(key),
+		},
+	}
+
+	_, err = k.client.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	k.log.Info("Created secret", "name", secretName, "from", body.From, "to", body.To)
+	return nil
+}
+
+// ResourceName turns the from of a record into the base name of its Secret
+// and Ingress. A wildcard host becomes wildcard.<domain>, since * is not
+// valid in a resource name.
+func ResourceName(from string) string {
+	if rest, ok := strings.CutPrefix(from, "*."); ok {
+		return "wildcard." + rest
+	}
+	return from
+}
+
 func (k Kube) DeleteProxy(namespace, name string) error {
-	ingressName := name + "-ingress"
-	secret := name + "-tls"
+	if utils.IsRegexHost(name) {
+		return nil
+	}
+	ingressName := ResourceName(name) + "-ingress"
+	secret := ResourceName(name) + "-tls"
 	ingress, err := k.client.NetworkingV1().Ingresses(namespace).Get(context.Background(), ingressName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// Layer 4 records have neither an Ingress nor a Secret.
+		k.log.Info("No ingress to delete", "ingress", ingressName)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get ingress: %v", err)
 	}
@@ -182,21 +229,22 @@ func (k Kube) DeleteProxy(namespace, name string) error {
 		return fmt.Errorf("ingress '%s' is not managed by prx and cannot be deleted", ingressName)
 	}
 
+	// Records using ACME have no Secret until their certificate is issued.
 	secrets, err := k.client.CoreV1().Secrets(namespace).Get(context.Background(), secret, metav1.GetOptions{})
-	if err != nil {
+	switch {
+	case apierrors.IsNotFound(err):
+		k.log.Info("No secret to delete", "secret", secret)
+	case err != nil:
 		return fmt.Errorf("failed to get ingress: %v", err)
-	}
-
-	if secrets.Labels == nil || secrets.Labels["managed-by"] != "prx" {
+	case secrets.Labels == nil || secrets.Labels["managed-by"] != "prx":
 		return fmt.Errorf("secret '%s' is not managed by prx and cannot be deleted", ingressName)
+	default:
+		if err := k.client.CoreV1().Secrets(namespace).Delete(context.Background(), secret, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to get secret: %v", err)
+		}
+		k.log.Info("Deleted", "secret", secret)
 	}
 
-	if err := k.client.CoreV1().Secrets(namespace).Delete(context.Background(), secret, metav1.DeleteOptions{}); err != nil {
-		return fmt.Errorf("failed to get secret: %v", err)
-	}
-
-	k.log.Info("Deleted", "secret", secret)
-
 	// Delete the ingress resource
 	if err := k.client.NetworkingV1().Ingresses(namespace).Delete(context.Background(), ingressName, metav1.DeleteOptions{}); err != nil {
 		return fmt.Errorf("failed to delete ingress: %v", err)
@@ -207,9 +255,172 @@ func (k Kube) DeleteProxy(namespace, name string) error {
 	return nil
 }
 
-func (k Kube) GetProxyMappings(namespace, configMapName string) (map[string]string, error) {
+// GetSecretData returns the data of a Secret in namespace.
+func (k Kube) GetSecretData(namespace, name string) (map[string][]byte, error) {
+	secret, err := k.client.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %v", name, err)
+	}
+	return secret.Data, nil
+}
 
-	res := make(map[string]string)
+// TLSSecret is a kubernetes.io/tls Secret managed by prx.
+type TLSSecret struct {
+	Name            string
+	ResourceVersion string
+	Cert            []byte
+	Key             []byte
+}
+
+// ListTLSSecrets returns the TLS Secrets prx manages in namespace.
+func (k Kube) ListTLSSecrets(namespace string) ([]TLSSecret, error) {
+	list, err := k.client.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: "managed-by=prx",
+		FieldSelector: "type=" + string(corev1.SecretTypeTLS),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tls secrets: %v", err)
+	}
+	res := make([]TLSSecret, 0, len(list.Items))
+	for _, secret := range list.Items {
+		res = append(res, TLSSecret{
+			Name:            secret.Name,
+			ResourceVersion: secret.ResourceVersion,
+			Cert:            secret.Data[corev1.TLSCertKey],
+			Key:             secret.Data[corev1.TLSPrivateKeyKey],
+		})
+	}
+	return res, nil
+}
+
+// StoredSecret is a Secret prx writes itself, such as the certificates and
+// challenges of the ACME client.
+type StoredSecret struct {
+	Name   string
+	TLS    bool
+	Labels map[string]string
+	Data   map[string][]byte
+}
+
+// SecretStore reads and writes the Secrets of one namespace. Get returns nil
+// when the Secret does not exist; List returns the Secrets carrying all of
+// labels.
+type SecretStore interface {
+	Get(ctx context.Context, name string) (*StoredSecret, error)
+	List(ctx context.Context, labels map[string]string) ([]StoredSecret, error)
+	Apply(secret StoredSecret) error
+	Delete(name string) error
+}
+
+type kubeSecrets struct {
+	kube      Kube
+	namespace string
+}
+
+// Secrets returns a SecretStore for namespace.
+func (k Kube) Secrets(namespace string) SecretStore {
+	return kubeSecrets{kube: k, namespace: namespace}
+}
+
+func (s kubeSecrets) Get(ctx context.Context, name string) (*StoredSecret, error) {
+	secret, err := s.kube.client.CoreV1().Secrets(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %v", name, err)
+	}
+	return &StoredSecret{
+		Name:   secret.Name,
+		TLS:    secret.Type == corev1.SecretTypeTLS,
+		Labels: secret.Labels,
+		Data:   secret.Data,
+	}, nil
+}
+
+func (s kubeSecrets) List(ctx context.Context, labels map[string]string) ([]StoredSecret, error) {
+	list, err := s.kube.client.CoreV1().Secrets(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %v", err)
+	}
+	res := make([]StoredSecret, 0, len(list.Items))
+	for _, secret := range list.Items {
+		res = append(res, StoredSecret{
+			Name:   secret.Name,
+			TLS:    secret.Type == corev1.SecretTypeTLS,
+			Labels: secret.Labels,
+			Data:   secret.Data,
+		})
+	}
+	return res, nil
+}
+
+// Apply creates the Secret or replaces the data of an existing one. Secrets
+// are always labelled managed-by=prx.
+func (s kubeSecrets) Apply(stored StoredSecret) error {
+	labels := map[string]string{"managed-by": "prx"}
+	for k, v := range stored.Labels {
+		labels[k] = v
+	}
+	secretType := corev1.SecretTypeOpaque
+	if stored.TLS {
+		secretType = corev1.SecretTypeTLS
+	}
+
+	secrets := s.kube.client.CoreV1().Secrets(s.namespace)
+	secret, err := secrets.Get(context.Background(), stored.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      stored.Name,
+				Labels:    labels,
+				Namespace: s.namespace,
+			},
+			Type: secretType,
+			Data: stored.Data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create secret %s: %v", stored.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s: %v", stored.Name, err)
+	}
+	if secret.Labels["managed-by"] != "prx" {
+		return fmt.Errorf("secret '%s' is not managed by prx and cannot be updated", stored.Name)
+	}
+
+	secret.Labels = labels
+	secret.Data = stored.Data
+	if _, err := secrets.Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s: %v", stored.Name, err)
+	}
+	return nil
+}
+
+func (s kubeSecrets) Delete(name string) error {
+	err := s.kube.client.CoreV1().Secrets(s.namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s: %v", name, err)
+	}
+	return nil
+}
+
+// GetConfigMapData returns the data of a ConfigMap in namespace.
+func (k Kube) GetConfigMapData(namespace, name string) (map[string]string, error) {
+	cm, err := k.client.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+	return cm.Data, nil
+}
+
+func (k Kube) GetProxyMappings(namespace, configMapName string) (map[string]ProxyMapping, error) {
+
+	res := make(map[string]ProxyMapping)
 
 	cm, err := k.client.CoreV1().ConfigMaps(namespace).Get(context.Background(), configMapName, metav1.GetOptions{})
 	if err != nil {
@@ -227,7 +438,7 @@ func (k Kube) GetProxyMappings(namespace, configMapName string) (map[string]stri
 	}
 
 	for _, v := range mappings {
-		res[v.From] = v.To
+		res[v.From] = v
 	}
 
 	return res, nil
@@ -295,6 +506,57 @@ func (k Kube) AddProxyMapping(namespace, configMapName string, newMapping ProxyM
 	return nil
 }
 
+// ModifyProxyMapping applies modify to the proxy mapping with the 'From'
+// field from as it is stored in the proxies.yaml file inside the specified
+// ConfigMap and returns the stored result. The update carries the resource
+// version it read, so when another writer got in between, modify runs again
+// on the newer mapping instead of overwriting it.
+func (k Kube) ModifyProxyMapping(namespace, configMapName, from string, modify func(*ProxyMapping) error) (ProxyMapping, error) {
+	var res ProxyMapping
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := k.client.CoreV1().ConfigMaps(namespace).Get(context.Background(), configMapName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get configmap: %w", err)
+		}
+
+		data, ok := cm.Data["proxies.yaml"]
+		if !ok {
+			return fmt.Errorf("proxies.yaml not found in configmap")
+		}
+
+		var mappings []ProxyMapping
+		if err := yaml.Unmarshal([]byte(data), &mappings); err != nil {
+			return fmt.Errorf("failed to unmarshal proxy mappings: %v", err)
+		}
+
+		i := slices.IndexFunc(mappings, func(m ProxyMapping) bool { return m.From == from })
+		if i < 0 {
+			return fmt.Errorf("mapping with from '%s' not found", from)
+		}
+		if err := modify(&mappings[i]); err != nil {
+			return err
+		}
+
+		updatedData, err := yaml.Marshal(mappings)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated proxy mappings: %v", err)
+		}
+
+		cm.Data["proxies.yaml"] = string(updatedData)
+		if _, err := k.client.CoreV1().ConfigMaps(namespace).Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update configmap: %w", err)
+		}
+		res = mappings[i]
+		return nil
+	})
+	if err != nil {
+		return ProxyMapping{}, err
+	}
+
+	k.log.Info("Updated record in configmap "+configMapName, "record", from)
+	return res, nil
+}
+
 // DeleteProxyMapping removes a proxy mapping from the proxies.yaml file inside the specified ConfigMap.
 // It identifies the mapping to be deleted by matching the 'From' field. If a mapping with the provided 'from' value
 // is not found, the method returns an error.
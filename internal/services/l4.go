@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// errHelloRead stops the handshake once the ClientHello was read.
+var errHelloRead = errors.New("client hello read")
+
+// PeekServerName reads the TLS ClientHello of conn and returns the server
+// name it asks for, without answering it. The returned conn replays the
+// bytes read, so the handshake can still be completed by the upstream or by
+// a local TLS server.
+func PeekServerName(conn net.Conn, timeout time.Duration) (string, net.Conn, error) {
+	var read bytes.Buffer
+	var serverName string
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	err := tls.Server(readOnlyConn{Reader: io.TeeReader(conn, &read)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, errHelloRead
+		},
+	}).Handshake()
+	conn.SetReadDeadline(time.Time{})
+
+	peeked := &peekedConn{Conn: conn, r: io.MultiReader(&read, conn)}
+	if !errors.Is(err, errHelloRead) {
+		return "", peeked, err
+	}
+	return serverName, peeked, nil
+}
+
+type peekedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (c *peekedConn) CloseWrite() error { return closeWrite(c.Conn) }
+
+func closeWrite(conn net.Conn) error {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return conn.Close()
+}
+
+// readOnlyConn feeds the ClientHello to a TLS server and drops its answer.
+type readOnlyConn struct {
+	io.Reader
+}
+
+func (readOnlyConn) Write(p []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (readOnlyConn) Close() error                       { return nil }
+func (readOnlyConn) LocalAddr() net.Addr                { return nil }
+func (readOnlyConn) RemoteAddr() net.Addr               { return nil }
+func (readOnlyConn) SetDeadline(t time.Time) error      { return nil }
+func (readOnlyConn) SetReadDeadline(t time.Time) error  { return nil }
+func (readOnlyConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// Splice copies between client and upstream until either side is done and
+// returns the bytes sent each way. With an idle timeout, the connections are
+// closed once no data flowed in either direction for that long.
+func Splice(client, upstream net.Conn, idle time.Duration) (sent, received int64) {
+	var wg sync.WaitGroup
+	var activity idleTracker
+	activity.touch()
+
+	copyHalf := func(dst, src net.Conn, n *int64) {
+		defer wg.Done()
+		buf := make([]byte, 32*1024)
+		for {
+			if idle > 0 {
+				src.SetReadDeadline(time.Now().Add(idle))
+			}
+			nr, err := src.Read(buf)
+			if nr > 0 {
+				activity.touch()
+				nw, werr := dst.Write(buf[:nr])
+				*n += int64(nw)
+				if werr != nil {
+					break
+				}
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() && activity.since() < idle {
+				// The other direction is still busy.
+				continue
+			}
+			if err == io.EOF {
+				// Half-close, so the other side can finish its answer.
+				closeWrite(dst)
+				return
+			}
+			if err != nil {
+				break
+			}
+		}
+		dst.Close()
+		src.Close()
+	}
+
+	wg.Add(2)
+	go copyHalf(upstream, client, &sent)
+	go copyHalf(client, upstream, &received)
+	wg.Wait()
+
+	client.Close()
+	upstream.Close()
+	return sent, received
+}
+
+type idleTracker struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (t *idleTracker) touch() {
+	t.mu.Lock()
+	t.last = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *idleTracker) since() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Since(t.last)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"prx/internal/models"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Mirror defaults used when a record or the PRX_MIRROR_* variables leave a
+// setting empty.
+const (
+	DefaultMirrorMaxBodyBytes = 64 << 10
+	DefaultMirrorMaxInFlight  = 100
+	DefaultMirrorTimeout      = 5 * time.Second
+)
+
+type mirrorCounters struct {
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+}
+
+// Mirror sends copies of live requests to shadow upstreams in the background
+// and throws the answers away. At most maxInFlight copies are outstanding;
+// copies beyond that are dropped so a slow shadow never holds up clients.
+type Mirror struct {
+	transport http.RoundTripper
+	log       *log.Logger
+	timeout   time.Duration
+	slots     chan struct{}
+
+	mu       sync.Mutex
+	counters map[string]*mirrorCounters
+}
+
+// NewMirrorFromEnv reads PRX_MIRROR_MAX_IN_FLIGHT and PRX_MIRROR_TIMEOUT.
+func NewMirrorFromEnv(logger *log.Logger, transport http.RoundTripper) *Mirror {
+	return NewMirror(logger, transport,
+		envInt("PRX_MIRROR_MAX_IN_FLIGHT", DefaultMirrorMaxInFlight),
+		envDuration("PRX_MIRROR_TIMEOUT", DefaultMirrorTimeout),
+	)
+}
+
+func NewMirror(logger *log.Logger, transport http.RoundTripper, maxInFlight int, timeout time.Duration) *Mirror {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMirrorMaxInFlight
+	}
+	return &Mirror{
+		transport: transport,
+		log:       logger,
+		timeout:   timeout,
+		slots:     make(chan struct{}, maxInFlight),
+		counters:  make(map[string]*mirrorCounters),
+	}
+}
+
+// Sample reports whether a request falls into the mirrored percentage. A
+// zero percent mirrors every request.
+func (m *Mirror) Sample(percent float64) bool {
+	return percent <= 0 || percent >= 100 || rand.Float64()*100 < percent
+}
+
+// Send sends req for the record from in the background. req must not share
+// its body with the live request. An answer below 500 counts as success.
+func (m *Mirror) Send(from string, req *http.Request) {
+	c := m.countersOf(from)
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		c.dropped.Add(1)
+		return
+	}
+
+	go func() {
+		defer func() { <-m.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		res, err := m.transport.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			c.failed.Add(1)
+			m.log.Debug("Mirrored request failed", "host", from, "target", req.URL.Host, "err", err)
+			return
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		if res.StatusCode >= http.StatusInternalServerError {
+			c.failed.Add(1)
+			return
+		}
+		c.succeeded.Add(1)
+	}()
+}
+
+// Drop counts a sampled request that could not be mirrored.
+func (m *Mirror) Drop(from string) {
+	m.countersOf(from).dropped.Add(1)
+}
+
+// Status returns the mirror counters of a record.
+func (m *Mirror) Status(from string) models.MirrorStatus {
+	c := m.countersOf(from)
+	return models.MirrorStatus{
+		Succeeded: c.succeeded.Load(),
+		Failed:    c.failed.Load(),
+		Dropped:   c.dropped.Load(),
+	}
+}
+
+// Forget drops the counters of a deleted record.
+func (m *Mirror) Forget(from string) {
+	m.mu.Lock()
+	delete(m.counters, from)
+	m.mu.Unlock()
+}
+
+func (m *Mirror) countersOf(from string) *mirrorCounters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.counters[from]
+	if !ok {
+		c = &mirrorCounters{}
+		m.counters[from] = c
+	}
+	return c
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"prx/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// proxyV2Signature starts every binary PROXY protocol header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// maxProxyV1Header is the longest text header the specification allows.
+const maxProxyV1Header = 107
+
+var errNoProxyHeader = errors.New("missing PROXY protocol header")
+
+// ProxyProtocolListener reads the PROXY protocol header that load balancers
+// put in front of a connection, so RemoteAddr and LocalAddr return the
+// addresses of the client and of the balancer's listener. Only connections
+// from trusted sources are expected to carry a header and must send one;
+// every other connection is returned untouched. The header is read on the
+// first Read or address lookup, so a slow client does not hold up Accept.
+type ProxyProtocolListener struct {
+	net.Listener
+	log     *log.Logger
+	trusted func(ip string) bool
+	timeout time.Duration
+}
+
+func NewProxyProtocolListener(logger *log.Logger, ln net.Listener, trusted func(ip string) bool, timeout time.Duration) *ProxyProtocolListener {
+	return &ProxyProtocolListener{Listener: ln, log: logger, trusted: trusted, timeout: timeout}
+}
+
+func (l *ProxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusted(addrIP(conn.RemoteAddr())) {
+		return conn, nil
+	}
+	return &proxyConn{Conn: conn, log: l.log, timeout: l.timeout}, nil
+}
+
+type proxyConn struct {
+	net.Conn
+	log     *log.Logger
+	timeout time.Duration
+
+	once     sync.Once
+	r        *bufio.Reader
+	src, dst net.Addr
+	err      error
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// readHeader consumes the header once. The read deadline the caller set is
+// restored afterwards.
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.r = bufio.NewReader(c.Conn)
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		c.src, c.dst, c.err = ReadProxyHeader(c.r)
+
+		c.mu.Lock()
+		c.Conn.SetReadDeadline(c.deadline)
+		c.mu.Unlock()
+
+		if c.err != nil {
+			c.log.Warn("Dropping connection without a valid PROXY protocol header", "source", c.Conn.RemoteAddr(), "err", c.err)
+			c.Conn.Close()
+		}
+	})
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(p)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.src != nil {
+		return c.src
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.dst != nil {
+		return c.dst
+	}
+	return c.Conn.LocalAddr()
+}
+
+func (c *proxyConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *proxyConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *proxyConn) CloseWrite() error { return closeWrite(c.Conn) }
+
+// ReadProxyHeader reads a PROXY protocol v1 or v2 header from r and returns
+// the source and destination it carries. Both are nil for headers without
+// addresses, such as the LOCAL command balancers use for health checks.
+func ReadProxyHeader(r *bufio.Reader) (src, dst net.Addr, err error) {
+	start, err := r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case bytes.Equal(start, proxyV2Signature):
+		return readProxyV2(r)
+	case bytes.HasPrefix(start, []byte("PROXY ")):
+		return readProxyV1(r)
+	}
+	return nil, nil, errNoProxyHeader
+}
+
+func readProxyV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= maxProxyV1Header {
+			return nil, nil, errors.New("PROXY protocol v1 header too long")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("invalid PROXY protocol v1 header %q", strings.TrimSpace(string(line)))
+	}
+	src, err := parseProxyV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := parseProxyV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func parseProxyV1Addr(ip, port string) (net.Addr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 address %q", ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 port %q", port)
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+func readProxyV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, nil, err
+	}
+	if head[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("unsupported PROXY protocol version %d", head[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, nil, err
+	}
+
+	switch head[12] & 0x0f {
+	case 0x0: // LOCAL
+		return nil, nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, nil, fmt.Errorf("unknown PROXY protocol v2 command %d", head[12]&0x0f)
+	}
+
+	var size int
+	switch head[13] {
+	case 0x11: // TCP over IPv4
+		size = 4
+	case 0x21: // TCP over IPv6
+		size = 16
+	default:
+		// UDP and unix sockets have no address prx could use.
+		return nil, nil, nil
+	}
+	if len(body) < 2*size+4 {
+		return nil, nil, errors.New("PROXY protocol v2 addresses truncated")
+	}
+	srcIP, _ := netip.AddrFromSlice(body[:size])
+	dstIP, _ := netip.AddrFromSlice(body[size : 2*size])
+	srcPort := binary.BigEndian.Uint16(body[2*size:])
+	dstPort := binary.BigEndian.Uint16(body[2*size+2:])
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(srcIP, srcPort)),
+		net.TCPAddrFromAddrPort(netip.AddrPortFrom(dstIP, dstPort)), nil
+}
+
+// WriteProxyHeader writes a PROXY protocol v1 or v2 header for a connection
+// from src to dst. Without usable addresses, such as for prx's own health
+// checks, the header tells the upstream to use those of the connection.
+func WriteProxyHeader(w io.Writer, version string, src, dst net.Addr) error {
+	s, sok := addrPort(src)
+	d, dok := addrPort(dst)
+	known := sok && dok && s.Addr().Is4() == d.Addr().Is4()
+
+	var buf bytes.Buffer
+	if version == models.ProxyProtocolV1 {
+		switch {
+		case !known:
+			buf.WriteString("PROXY UNKNOWN\r\n")
+		case s.Addr().Is4():
+			fmt.Fprintf(&buf, "PROXY TCP4 %s %s %d %d\r\n", s.Addr(), d.Addr(), s.Port(), d.Port())
+		default:
+			fmt.Fprintf(&buf, "PROXY TCP6 %s %s %d %d\r\n", s.Addr(), d.Addr(), s.Port(), d.Port())
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	buf.Write(proxyV2Signature)
+	if !known {
+		buf.Write([]byte{0x20, 0x00, 0x00, 0x00})
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	if s.Addr().Is4() {
+		buf.Write([]byte{0x21, 0x11, 0x00, 12})
+	} else {
+		buf.Write([]byte{0x21, 0x21, 0x00, 36})
+	}
+	buf.Write(s.Addr().AsSlice())
+	buf.Write(d.Addr().AsSlice())
+	binary.Write(&buf, binary.BigEndian, s.Port())
+	binary.Write(&buf, binary.BigEndian, d.Port())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func addrPort(addr net.Addr) (netip.AddrPort, bool) {
+	if addr == nil {
+		return netip.AddrPort{}, false
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()), true
+}
+
+func addrIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+type proxyAddrsKey struct{}
+
+type proxyAddrs struct {
+	src, dst net.Addr
+}
+
+// WithProxyAddrs records the client a request is proxied for, so the
+// upstream connection dialled for it announces the client's address.
+func WithProxyAddrs(ctx context.Context, src, dst net.Addr) context.Context {
+	return context.WithValue(ctx, proxyAddrsKey{}, proxyAddrs{src: src, dst: dst})
+}
+
+// DialFunc dials an upstream connection, as http.Transport.DialContext does.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ProxyProtocolDialer starts every connection dial opens with a PROXY
+// protocol header for the addresses stored by WithProxyAddrs. The transport
+// using it must not reuse connections, or requests of one client would
+// arrive on the connection of another.
+func ProxyProtocolDialer(version string, dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, _ := ctx.Value(proxyAddrsKey{}).(proxyAddrs)
+		if err := WriteProxyHeader(conn, version, addrs.src, addrs.dst); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
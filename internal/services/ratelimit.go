@@ -0,0 +1,122 @@
+package services
+
+import (
+	"math"
+	"os"
+	"prx/internal/models"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimitPeriod is used when a rate limit leaves Period empty.
+const DefaultRateLimitPeriod = time.Second
+
+// rateLimitSweepInterval is how often idle buckets are dropped.
+const rateLimitSweepInterval = time.Minute
+
+// RateDecision is the outcome of RateLimiter.Allow together with the values
+// for the RateLimit-* response headers.
+type RateDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Window     time.Duration
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, only set when denied
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter keeps one token bucket per client key.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	rate   float64 // tokens per second
+	burst  float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewRateLimiter(cfg models.RateLimit) *RateLimiter {
+	window := durationOr(cfg.Period, DefaultRateLimitPeriod)
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.Requests
+	}
+	return &RateLimiter{
+		limit:     cfg.Requests,
+		window:    window,
+		rate:      float64(cfg.Requests) / window.Seconds(),
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// RateLimitFromEnv reads the global rate limit from PRX_RATE_LIMIT_REQUESTS,
+// PRX_RATE_LIMIT_PERIOD, PRX_RATE_LIMIT_BURST, PRX_RATE_LIMIT_KEY and
+// PRX_RATE_LIMIT_HEADER. It returns nil when no global limit is set.
+func RateLimitFromEnv() *models.RateLimit {
+	requests := envInt("PRX_RATE_LIMIT_REQUESTS", 0)
+	if requests <= 0 {
+		return nil
+	}
+	return &models.RateLimit{
+		Requests: requests,
+		Period:   os.Getenv("PRX_RATE_LIMIT_PERIOD"),
+		Burst:    envInt("PRX_RATE_LIMIT_BURST", 0),
+		Key:      os.Getenv("PRX_RATE_LIMIT_KEY"),
+		Header:   os.Getenv("PRX_RATE_LIMIT_HEADER"),
+	}
+}
+
+// Allow takes a token from the bucket of key.
+func (l *RateLimiter) Allow(key string) RateDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*l.rate, l.burst)
+	b.last = now
+
+	d := RateDecision{Limit: l.limit, Window: l.window}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.until(1 - b.tokens)
+	}
+	d.Remaining = int(math.Floor(b.tokens))
+	d.Reset = l.until(l.burst - b.tokens)
+	return d
+}
+
+func (l *RateLimiter) until(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have refilled completely, they behave the
+// same as a new bucket. Callers must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
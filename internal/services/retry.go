@@ -0,0 +1,94 @@
+package services
+
+import (
+	"math/rand/v2"
+	"prx/internal/models"
+	"sync"
+	"time"
+)
+
+// Retry defaults used when a record leaves a setting empty.
+const (
+	DefaultRetryBaseBackoff    = 25 * time.Millisecond
+	DefaultRetryMaxBackoff     = 250 * time.Millisecond
+	DefaultRetryMaxBufferBytes = 1 << 20
+)
+
+// Retry conditions a record can opt into besides status codes.
+const (
+	RetryOnConnectFailure = "connect-failure"
+	RetryOnReset          = "reset"
+)
+
+// RetryBudget caps retries across all records so a struggling upstream is
+// not buried under a retry storm. Every retryable request deposits ratio
+// tokens, every retry withdraws one, and minPerSecond tokens are refilled
+// each second so low traffic hosts can still retry.
+type RetryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	maxTokens    float64
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+}
+
+// NewRetryBudgetFromEnv reads PRX_RETRY_BUDGET_RATIO (default 0.2) and
+// PRX_RETRY_BUDGET_MIN_PER_SECOND (default 10).
+func NewRetryBudgetFromEnv() *RetryBudget {
+	ratio := envFloat("PRX_RETRY_BUDGET_RATIO", 0.2)
+	minPerSecond := envFloat("PRX_RETRY_BUDGET_MIN_PER_SECOND", 10)
+	return NewRetryBudget(ratio, minPerSecond)
+}
+
+func NewRetryBudget(ratio, minPerSecond float64) *RetryBudget {
+	maxTokens := max(10*minPerSecond, 1)
+	return &RetryBudget{
+		ratio:        ratio,
+		minPerSecond: minPerSecond,
+		maxTokens:    maxTokens,
+		tokens:       maxTokens,
+		last:         time.Now(),
+	}
+}
+
+// Deposit is called once per proxied request that may be retried.
+func (b *RetryBudget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// Withdraw reports whether a retry fits in the budget and books it.
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.minPerSecond, b.maxTokens)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RetryBackoffs returns the base and maximum backoff of a retry policy.
+func RetryBackoffs(p models.RetryPolicy) (base, limit time.Duration) {
+	base = durationOr(p.BaseBackoff, DefaultRetryBaseBackoff)
+	limit = durationOr(p.MaxBackoff, DefaultRetryMaxBackoff)
+	return base, max(base, limit)
+}
+
+// Backoff returns the delay before the given retry (1 for the first retry)
+// using exponential backoff with full jitter.
+func Backoff(retry int, base, limit time.Duration) time.Duration {
+	d := base << (retry - 1)
+	if d <= 0 || d > limit {
+		d = limit
+	}
+	return rand.N(d + 1)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TransportSettings tunes the connection pool shared by every upstream request.
+type TransportSettings struct {
+	DialTimeout            time.Duration
+	KeepAlive              time.Duration
+	IdleConnTimeout        time.Duration
+	TLSHandshakeTimeout    time.Duration
+	ExpectContinueTimeout  time.Duration
+	ResponseHeaderTimeout  time.Duration
+	MaxResponseHeaderBytes int64
+	MaxIdleConns           int
+	MaxIdleConnsPerHost    int
+	MaxConnsPerHost        int
+	HTTP2                  bool
+}
+
+// TransportSettingsFromEnv reads the PRX_UPSTREAM_* variables, falling back
+// to defaults sized for a proxy rather than for a single client.
+func TransportSettingsFromEnv() TransportSettings {
+	return TransportSettings{
+		DialTimeout:            envTimeout("PRX_UPSTREAM_DIAL_TIMEOUT", 5*time.Second),
+		KeepAlive:              envDuration("PRX_UPSTREAM_KEEPALIVE", 30*time.Second),
+		IdleConnTimeout:        envTimeout("PRX_UPSTREAM_IDLE_CONN_TIMEOUT", 90*time.Second),
+		TLSHandshakeTimeout:    envTimeout("PRX_UPSTREAM_TLS_HANDSHAKE_TIMEOUT", 10*time.Second),
+		ExpectContinueTimeout:  envTimeout("PRX_UPSTREAM_EXPECT_CONTINUE_TIMEOUT", 1*time.Second),
+		ResponseHeaderTimeout:  envTimeout("PRX_UPSTREAM_RESPONSE_HEADER_TIMEOUT", 60*time.Second),
+		MaxResponseHeaderBytes: int64(envInt("PRX_UPSTREAM_MAX_RESPONSE_HEADER_BYTES", 1<<20)),
+		MaxIdleConns:           envInt("PRX_UPSTREAM_MAX_IDLE_CONNS", 1000),
+		MaxIdleConnsPerHost:    envInt("PRX_UPSTREAM_MAX_IDLE_CONNS_PER_HOST", 100),
+		MaxConnsPerHost:        envInt("PRX_UPSTREAM_MAX_CONNS_PER_HOST", 0),
+		HTTP2:                  envBool("PRX_UPSTREAM_HTTP2", true),
+	}
+}
+
+// NewTransport builds the pooled transport used for all upstream requests.
+func NewTransport(s TransportSettings) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   s.DialTimeout,
+		KeepAlive: s.KeepAlive,
+	}
+	return &http.Transport{
+		Proxy:                  http.ProxyFromEnvironment,
+		DialContext:            dialer.DialContext,
+		ForceAttemptHTTP2:      s.HTTP2,
+		MaxIdleConns:           s.MaxIdleConns,
+		MaxIdleConnsPerHost:    s.MaxIdleConnsPerHost,
+		MaxConnsPerHost:        s.MaxConnsPerHost,
+		IdleConnTimeout:        s.IdleConnTimeout,
+		TLSHandshakeTimeout:    s.TLSHandshakeTimeout,
+		ExpectContinueTimeout:  s.ExpectContinueTimeout,
+		ResponseHeaderTimeout:  s.ResponseHeaderTimeout,
+		MaxResponseHeaderBytes: s.MaxResponseHeaderBytes,
+	}
+}
+
+// ServerSettings bound what clients may send to the proxy listener and hold
+// the defaults of the per-record total timeout and body size limit.
+type ServerSettings struct {
+	ReadHeaderTimeout   time.Duration
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	IdleTimeout         time.Duration
+	MaxHeaderBytes      int
+	RequestTimeout      time.Duration
+	MaxRequestBodyBytes int64
+}
+
+// ServerSettingsFromEnv reads the PRX_SERVER_* variables, PRX_UPSTREAM_TIMEOUT
+// and PRX_MAX_REQUEST_BODY_BYTES. Read and write timeouts default to none so
+// long uploads and streamed responses keep working.
+func ServerSettingsFromEnv() ServerSettings {
+	return ServerSettings{
+		ReadHeaderTimeout:   envTimeout("PRX_SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+		ReadTimeout:         envTimeout("PRX_SERVER_READ_TIMEOUT", 0),
+		WriteTimeout:        envTimeout("PRX_SERVER_WRITE_TIMEOUT", 0),
+		IdleTimeout:         envTimeout("PRX_SERVER_IDLE_TIMEOUT", 120*time.Second),
+		MaxHeaderBytes:      envInt("PRX_SERVER_MAX_HEADER_BYTES", 1<<20),
+		RequestTimeout:      envTimeout("PRX_UPSTREAM_TIMEOUT", 0),
+		MaxRequestBodyBytes: int64(envInt("PRX_MAX_REQUEST_BODY_BYTES", 0)),
+	}
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	if v := os.Getenv(name); v != "" {
+		return durationOr(v, def)
+	}
+	return def
+}
+
+// envTimeout reads a timeout where an explicit "0s" turns it off. Unset or
+// unparsable values, and negative ones, use the default.
+func envTimeout(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
+}
+
+func envInt(name string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+func envBool(name string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func envFloat(name string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"prx/internal/models"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// DefaultUpstreamTLSTTL is how long CA bundles and client certificates are
+// kept before their Secret is read again.
+const DefaultUpstreamTLSTTL = time.Minute
+
+// Keys read from upstream TLS Secrets, the ones kubernetes.io/tls Secrets
+// and cert-manager use.
+const (
+	UpstreamCAKey   = "ca.crt"
+	UpstreamCertKey = "tls.crt"
+	UpstreamKeyKey  = "tls.key"
+)
+
+type tlsSecret struct {
+	roots   *x509.CertPool
+	cert    *tls.Certificate
+	fetched time.Time
+}
+
+// UpstreamTLS builds the TLS client configuration of records talking to
+// HTTPS upstreams with a private CA or mutual TLS. Certificate material is
+// read from Secrets during the handshake and kept for ttl, so rotated
+// certificates take effect without restarting prx.
+type UpstreamTLS struct {
+	secrets SecretReader
+	log     *log.Logger
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]tlsSecret
+}
+
+// NewUpstreamTLSFromEnv reads the reload interval from PRX_UPSTREAM_TLS_TTL.
+func NewUpstreamTLSFromEnv(logger *log.Logger, secrets SecretReader) *UpstreamTLS {
+	return NewUpstreamTLS(logger, secrets, envDuration("PRX_UPSTREAM_TLS_TTL", DefaultUpstreamTLSTTL))
+}
+
+func NewUpstreamTLS(logger *log.Logger, secrets SecretReader, ttl time.Duration) *UpstreamTLS {
+	return &UpstreamTLS{
+		secrets: secrets,
+		log:     logger,
+		ttl:     ttl,
+		entries: make(map[string]tlsSecret),
+	}
+}
+
+// Config returns the TLS client configuration for cfg towards host. A CA
+// Secret replaces the system roots; the chain is then verified by
+// VerifyConnection, as the roots can change after the transport was built,
+// against cfg.ServerName or else host, which may be an IP.
+func (u *UpstreamTLS) Config(cfg models.UpstreamTLS, host string) *tls.Config {
+	name := cfg.ServerName
+	if name == "" {
+		name = host
+	}
+	conf := &tls.Config{
+		ServerName:         name,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if cfg.CASecret != "" && !cfg.InsecureSkipVerify {
+		conf.InsecureSkipVerify = true
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			return u.verify(cfg.CASecret, name, cs)
+		}
+	}
+	if cfg.CertSecret != "" {
+		conf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			s, err := u.secretOf(cfg.CertSecret)
+			if err != nil {
+				return nil, err
+			}
+			if s.cert == nil {
+				return nil, fmt.Errorf("secret %s has no %s and %s", cfg.CertSecret, UpstreamCertKey, UpstreamKeyKey)
+			}
+			return s.cert, nil
+		}
+	}
+	return conf
+}
+
+// DialTLS returns a DialTLSContext that gives every connection the
+// configuration of the host it dials, so upstreams verified against a CA
+// Secret are checked for that host. The transport does this by itself for
+// the system roots but cannot for VerifyConnection.
+func (u *UpstreamTLS) DialTLS(cfg models.UpstreamTLS, dial DialFunc, nextProtos []string, timeout time.Duration) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conf := u.Config(cfg, host)
+		conf.NextProtos = nextProtos
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		tlsConn := tls.Client(conn, conf)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+func (u *UpstreamTLS) verify(secret, name string, cs tls.ConnectionState) error {
+	s, err := u.secretOf(secret)
+	if err != nil {
+		return err
+	}
+	if s.roots == nil {
+		return fmt.Errorf("secret %s has no %s", secret, UpstreamCAKey)
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("upstream sent no certificate")
+	}
+	if name == "" {
+		return errors.New("no server name to verify the upstream certificate against, set upstreamTLS.serverName")
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         s.roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// secretOf returns the parsed material of a Secret, reading it again after
+// ttl. The last good material is kept while the Secret cannot be read.
+func (u *UpstreamTLS) secretOf(name string) (tlsSecret, error) {
+	u.mu.Lock()
+	entry, ok := u.entries[name]
+	u.mu.Unlock()
+	if ok && time.Since(entry.fetched) < u.ttl {
+		return entry, nil
+	}
+
+	data, err := u.secrets(name)
+	if err == nil {
+		entry, err = parseTLSSecret(data)
+	}
+	if err != nil {
+		if ok {
+			u.log.Error("Failed to reload upstream TLS secret, keeping the last one", "secret", name, "err", err)
+			return entry, nil
+		}
+		return tlsSecret{}, fmt.Errorf("secret %s: %v", name, err)
+	}
+
+	u.mu.Lock()
+	u.entries[name] = entry
+	u.mu.Unlock()
+	return entry, nil
+}
+
+func parseTLSSecret(data map[string][]byte) (tlsSecret, error) {
+	s := tlsSecret{fetched: time.Now()}
+	if ca := data[UpstreamCAKey]; len(ca) > 0 {
+		s.roots = x509.NewCertPool()
+		if !s.roots.AppendCertsFromPEM(ca) {
+			return s, fmt.Errorf("%s holds no PEM certificates", UpstreamCAKey)
+		}
+	}
+	if len(data[UpstreamCertKey]) > 0 || len(data[UpstreamKeyKey]) > 0 {
+		cert, err := tls.X509KeyPair(data[UpstreamCertKey], data[UpstreamKeyKey])
+		if err != nil {
+			return s, err
+		}
+		s.cert = &cert
+	}
+	return s, nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// CIDRList is a set of address ranges. Single addresses count as /32 or
+// /128 ranges.
+type CIDRList []netip.Prefix
+
+// ParseCIDRs parses CIDR ranges and plain IP addresses.
+func ParseCIDRs(values []string) (CIDRList, error) {
+	var res CIDRList
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ip %q", v)
+			}
+			res = append(res, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q", v)
+		}
+		res = append(res, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Match returns the first range containing ip. IPv4-mapped IPv6 addresses
+// match IPv4 ranges.
+func (l CIDRList) Match(ip string) (netip.Prefix, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	for _, p := range l {
+		if p.Contains(addr) {
+			return p, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// Contains reports whether any range contains ip.
+func (l CIDRList) Contains(ip string) bool {
+	_, ok := l.Match(ip)
+	return ok
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RegexHostPrefix marks the from of a record as a regular expression that is
+// matched against the whole request host.
+const RegexHostPrefix = "~"
+
+// hostLabel is a {N} placeholder for the Nth matched label of a host pattern.
+var hostLabel = regexp.MustCompile(`\{([1-9][0-9]*)\}`)
+
+// HostPattern is the from of a wildcard (*.example.com) or regex
+// (~^(.+)\.example\.com$) record. A wildcard matches exactly one label, the
+// same as an Ingress wildcard host.
+type HostPattern struct {
+	From   string
+	suffix string
+	re     *regexp.Regexp
+}
+
+// IsWildcardHost reports whether from is a wildcard host.
+func IsWildcardHost(from string) bool {
+	return strings.HasPrefix(from, "*.")
+}
+
+// IsRegexHost reports whether from is a regex host.
+func IsRegexHost(from string) bool {
+	return strings.HasPrefix(from, RegexHostPrefix)
+}
+
+// ParseHostPattern compiles the from of a wildcard or regex record. It
+// returns nil for an exact host.
+func ParseHostPattern(from string) (*HostPattern, error) {
+	switch {
+	case IsWildcardHost(from):
+		suffix := strings.ToLower(from[1:])
+		if suffix == "." || strings.Contains(suffix, "*") {
+			return nil, fmt.Errorf("wildcard host %s must be *.<domain>", from)
+		}
+		return &HostPattern{From: from, suffix: suffix}, nil
+	case IsRegexHost(from):
+		re, err := regexp.Compile(`^(?:` + strings.TrimPrefix(from, RegexHostPrefix) + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("regex host %s is not valid: %v", from, err)
+		}
+		return &HostPattern{From: from, re: re}, nil
+	}
+	return nil, nil
+}
+
+// IsRegex reports whether p is a regex pattern.
+func (p *HostPattern) IsRegex() bool {
+	return p.re != nil
+}
+
+// Labels returns how many {N} placeholders the pattern can fill.
+func (p *HostPattern) Labels() int {
+	if p.re != nil {
+		return p.re.NumSubexp()
+	}
+	return 1
+}
+
+// Specificity orders wildcard patterns, a longer suffix is more specific.
+func (p *HostPattern) Specificity() int {
+	return len(p.suffix)
+}
+
+// Match matches a request host, with or without port, and returns the
+// matched labels: the label under * for a wildcard, the capture groups for a
+// regex.
+func (p *HostPattern) Match(host string) ([]string, bool) {
+	host = NormalizeHost(host)
+	if p.re == nil {
+		label, ok := strings.CutSuffix(host, p.suffix)
+		if !ok || label == "" || strings.Contains(label, ".") {
+			return nil, false
+		}
+		return []string{label}, true
+	}
+
+	m := p.re.FindStringSubmatch(host)
+	if m == nil {
+		return nil, false
+	}
+	return m[1:], true
+}
+
+// NormalizeHost lower-cases a request host and drops its port and trailing dot.
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// ExpandHostLabels replaces the {N} placeholders in s with the matched labels.
+// Placeholders without a label are left untouched.
+func ExpandHostLabels(s string, labels []string) string {
+	if len(labels) == 0 || !strings.Contains(s, "{") {
+		return s
+	}
+	return hostLabel.ReplaceAllStringFunc(s, func(ph string) string {
+		n, _ := strconv.Atoi(ph[1 : len(ph)-1])
+		if n > len(labels) {
+			return ph
+		}
+		return labels[n-1]
+	})
+}
+
+// HasHostLabels reports whether s contains a {N} placeholder.
+func HasHostLabels(s string) bool {
+	return hostLabel.MatchString(s)
+}
+
+// maxHostLabel returns the highest {N} placeholder used in s.
+func maxHostLabel(s string) int {
+	highest := 0
+	for _, m := range hostLabel.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		highest = max(highest, n)
+	}
+	return highest
+}
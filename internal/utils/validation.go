@@ -2,8 +2,17 @@ package utils
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"prx/internal/models"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func ValidateFields(input any) string {
@@ -31,6 +40,11 @@ func ValidateFields(input any) string {
 			continue
 		}
 
+		// Fields tagged omitempty are optional.
+		if strings.Contains(fieldType.Tag.Get("json"), ",omitempty") {
+			continue
+		}
+
 		// Check for blank strings.
 		if fieldValue.Kind() == reflect.String && fieldValue.String() == "" {
 			invalidProps = append(invalidProps, fmt.Sprintf("%s is blank", fieldType.Name))
@@ -49,3 +63,684 @@ func ValidateFields(input any) string {
 	}
 	return ""
 }
+
+// ValidateProxySpec checks the optional routing settings of a record. A
+// record needs either a default target or at least one upstream.
+func ValidateProxySpec(from, to string, spec models.ProxySpec) string {
+	if errMsg := ValidateHostLabels(from, to, spec); errMsg != "" {
+		return errMsg
+	}
+	to, spec = withSampleLabels(to, spec)
+
+	if spec.Protocol == models.ProtocolTLS || spec.Protocol == models.ProtocolTCP {
+		return ValidateL4Spec(from, to, spec)
+	}
+
+	switch spec.Mode {
+	case "", models.ModeProxy:
+		if spec.Redirect != nil {
+			return "redirect is only valid for mode redirect"
+		}
+	case models.ModeRedirect:
+		return ValidateRedirectSpec(to, spec)
+	default:
+		return fmt.Sprintf("unknown mode %s", spec.Mode)
+	}
+
+	var invalidProps []string
+
+	switch spec.Protocol {
+	case "", models.ProtocolHTTP, models.ProtocolHTTP2:
+	case models.ProtocolGRPC:
+		if spec.Cache != nil || spec.Mirror != nil || spec.Retry != nil {
+			invalidProps = append(invalidProps, "cache, mirror and retry buffer requests and are not valid for protocol grpc")
+		}
+	default:
+		invalidProps = append(invalidProps, fmt.Sprintf("unknown protocol %s", spec.Protocol))
+	}
+	if spec.ListenPort != 0 {
+		invalidProps = append(invalidProps, "listenPort is only valid for protocol tcp")
+	}
+	if errMsg := ValidateProxyProtocol(spec.ProxyProtocol); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	} else if spec.ProxyProtocol != "" && (spec.Protocol == models.ProtocolHTTP2 || spec.Protocol == models.ProtocolGRPC) {
+		// HTTP/2 multiplexes the requests of many clients on one connection.
+		invalidProps = append(invalidProps, fmt.Sprintf("proxyProtocol is not valid for protocol %s", spec.Protocol))
+	}
+
+	if to == "" && len(spec.Upstreams) == 0 {
+		invalidProps = append(invalidProps, "To is blank and no upstreams given")
+	}
+	if to != "" && !isAbsoluteURL(to) {
+		invalidProps = append(invalidProps, "to is not a valid url")
+	}
+
+	for i, u := range spec.Upstreams {
+		if !isAbsoluteURL(u.URL) {
+			invalidProps = append(invalidProps, fmt.Sprintf("upstreams[%d].url is not a valid url", i))
+		}
+		if u.Weight < 0 {
+			invalidProps = append(invalidProps, fmt.Sprintf("upstreams[%d].weight must not be negative", i))
+		}
+	}
+
+	switch spec.Policy {
+	case "", models.PolicyRoundRobin, models.PolicyWeightedRandom, models.PolicyLeastConn, models.PolicyIPHash:
+	case models.PolicyHeaderHash:
+		if spec.HashHeader == "" {
+			invalidProps = append(invalidProps, "hashHeader is required for policy header_hash")
+		}
+	default:
+		invalidProps = append(invalidProps, fmt.Sprintf("unknown policy %s", spec.Policy))
+	}
+
+	if a := spec.Affinity; a != nil {
+		switch a.Type {
+		case models.AffinityCookie, models.AffinityIP:
+		case models.AffinityHeader:
+			if a.Header == "" {
+				invalidProps = append(invalidProps, "affinity.header is required for header affinity")
+			}
+		default:
+			invalidProps = append(invalidProps, fmt.Sprintf("unknown affinity type %s", a.Type))
+		}
+		if !isDuration(a.CookieTTL) {
+			invalidProps = append(invalidProps, fmt.Sprintf("affinity.cookieTTL %s is not a valid duration", a.CookieTTL))
+		}
+		if len(spec.Upstreams) == 0 {
+			invalidProps = append(invalidProps, "affinity needs upstreams")
+		}
+	}
+
+	if hc := spec.HealthCheck; hc != nil {
+		if !strings.HasPrefix(hc.Path, "/") {
+			invalidProps = append(invalidProps, "healthCheck.path must start with /")
+		}
+		if !isDuration(hc.Interval) {
+			invalidProps = append(invalidProps, "healthCheck.interval is not a valid duration")
+		}
+		if !isDuration(hc.Timeout) {
+			invalidProps = append(invalidProps, "healthCheck.timeout is not a valid duration")
+		}
+		if hc.HealthyThreshold < 0 || hc.UnhealthyThreshold < 0 {
+			invalidProps = append(invalidProps, "healthCheck thresholds must not be negative")
+		}
+	}
+
+	if cb := spec.CircuitBreaker; cb != nil {
+		if !isDuration(cb.Cooldown) {
+			invalidProps = append(invalidProps, "circuitBreaker.cooldown is not a valid duration")
+		}
+		if cb.ConsecutiveFailures < 0 || cb.SuccessThreshold < 0 {
+			invalidProps = append(invalidProps, "circuitBreaker thresholds must not be negative")
+		}
+	}
+
+	if r := spec.Retry; r != nil {
+		if r.Attempts < 1 {
+			invalidProps = append(invalidProps, "retry.attempts must be at least 1")
+		}
+		for _, cond := range r.RetryOn {
+			if cond != "connect-failure" && cond != "reset" {
+				invalidProps = append(invalidProps, fmt.Sprintf("unknown retry condition %s", cond))
+			}
+		}
+		for _, code := range r.StatusCodes {
+			if code < 100 || code > 599 {
+				invalidProps = append(invalidProps, fmt.Sprintf("retry status code %d is not valid", code))
+			}
+		}
+		if !isDuration(r.BaseBackoff) || !isDuration(r.MaxBackoff) {
+			invalidProps = append(invalidProps, "retry backoff is not a valid duration")
+		}
+		if r.MaxBufferBytes < 0 {
+			invalidProps = append(invalidProps, "retry.maxBufferBytes must not be negative")
+		}
+	}
+
+	if c := spec.Cache; c != nil {
+		if !isDuration(c.DefaultTTL) {
+			invalidProps = append(invalidProps, fmt.Sprintf("cache.defaultTTL %s is not a valid duration", c.DefaultTTL))
+		}
+		if c.MaxObjectBytes < 0 {
+			invalidProps = append(invalidProps, "cache.maxObjectBytes must not be negative")
+		}
+	}
+
+	if rl := spec.RateLimit; rl != nil {
+		if errMsg := ValidateRateLimit(*rl); errMsg != "" {
+			invalidProps = append(invalidProps, errMsg)
+		}
+	}
+
+	if errMsg := ValidateHeaderRules("requestHeaders", spec.RequestHeaders); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateHeaderRules("responseHeaders", spec.ResponseHeaders); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+
+	if m := spec.Mirror; m != nil {
+		if !isAbsoluteURL(m.URL) {
+			invalidProps = append(invalidProps, "mirror.url is not a valid url")
+		}
+		if m.Percent < 0 || m.Percent > 100 {
+			invalidProps = append(invalidProps, "mirror.percent must be between 0 and 100")
+		}
+		if m.MaxBodyBytes < 0 {
+			invalidProps = append(invalidProps, "mirror.maxBodyBytes must not be negative")
+		}
+	}
+
+	if errMsg := ValidateLimits(spec.Limits); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateUpstreamTLS(spec.UpstreamTLS); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateCanary(spec); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateAuth(spec.Auth); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateIPAccess(spec.IPAccess); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateCORS(spec.CORS); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateMaintenance(spec.Maintenance); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+
+	if errMsg := ValidatePathRules(spec.Routes); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateRedirectSpec checks a redirect record. Settings that only apply to
+// proxied traffic are rejected rather than silently ignored.
+func ValidateRedirectSpec(to string, spec models.ProxySpec) string {
+	var invalidProps []string
+
+	if !isAbsoluteURL(to) {
+		invalidProps = append(invalidProps, "to must be a valid url for mode redirect")
+	}
+	if len(spec.Upstreams) > 0 || len(spec.Routes) > 0 || spec.HealthCheck != nil ||
+		spec.CircuitBreaker != nil || spec.Retry != nil || spec.Cache != nil || spec.RequestHeaders != nil || spec.Mirror != nil || spec.Canary != nil || spec.Affinity != nil || spec.UpstreamTLS != nil || spec.Limits != nil || spec.Protocol != "" || spec.ProxyProtocol != "" {
+		invalidProps = append(invalidProps, "upstreams, routes, healthCheck, circuitBreaker, retry, cache, requestHeaders, mirror, canary, affinity, upstreamTLS, limits, protocol and proxyProtocol are not valid for mode redirect")
+	}
+
+	if r := spec.Redirect; r != nil {
+		switch r.Status {
+		case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			invalidProps = append(invalidProps, fmt.Sprintf("redirect status %d is not a redirect", r.Status))
+		}
+		for i, rw := range r.Rewrites {
+			if _, err := regexp.Compile(rw.Match); err != nil || rw.Match == "" {
+				invalidProps = append(invalidProps, fmt.Sprintf("redirect.rewrites[%d].match is not a valid regular expression", i))
+			}
+		}
+	}
+
+	if spec.RateLimit != nil {
+		if errMsg := ValidateRateLimit(*spec.RateLimit); errMsg != "" {
+			invalidProps = append(invalidProps, errMsg)
+		}
+	}
+	if errMsg := ValidateHeaderRules("responseHeaders", spec.ResponseHeaders); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateAuth(spec.Auth); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateIPAccess(spec.IPAccess); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateCORS(spec.CORS); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if errMsg := ValidateMaintenance(spec.Maintenance); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateL4Spec checks a tls or tcp record. Its target is a host:port and
+// only the IP access lists, connectTimeout and idleTimeout apply; settings of
+// HTTP records are rejected rather than silently ignored.
+func ValidateL4Spec(from, to string, spec models.ProxySpec) string {
+	var invalidProps []string
+
+	if spec.Mode != "" && spec.Mode != models.ModeProxy {
+		invalidProps = append(invalidProps, fmt.Sprintf("mode %s is not valid for protocol %s", spec.Mode, spec.Protocol))
+	}
+	if !isHostPort(to) {
+		invalidProps = append(invalidProps, fmt.Sprintf("to must be a host:port for protocol %s", spec.Protocol))
+	}
+
+	switch spec.Protocol {
+	case models.ProtocolTLS:
+		if IsRegexHost(from) {
+			invalidProps = append(invalidProps, "from must be a host or wildcard host for protocol tls")
+		}
+		if spec.ListenPort != 0 {
+			invalidProps = append(invalidProps, "listenPort is only valid for protocol tcp")
+		}
+	case models.ProtocolTCP:
+		switch {
+		case spec.ListenPort < 1 || spec.ListenPort > 65535:
+			invalidProps = append(invalidProps, "listenPort must be between 1 and 65535 for protocol tcp")
+		case spec.ListenPort == 80 || spec.ListenPort == 443 || spec.ListenPort == 50051:
+			invalidProps = append(invalidProps, fmt.Sprintf("listenPort %d is used by prx", spec.ListenPort))
+		}
+	}
+
+	if len(spec.Upstreams) > 0 || len(spec.Routes) > 0 || spec.Policy != "" || spec.HealthCheck != nil || spec.CircuitBreaker != nil ||
+		spec.Retry != nil || spec.Cache != nil || spec.RateLimit != nil || spec.RequestHeaders != nil || spec.ResponseHeaders != nil ||
+		spec.Redirect != nil || spec.Mirror != nil || spec.Canary != nil || spec.Affinity != nil || spec.Auth != nil || spec.CORS != nil ||
+		spec.Maintenance != nil || spec.ErrorPages != "" || spec.UpstreamTLS != nil {
+		invalidProps = append(invalidProps, fmt.Sprintf("only to, listenPort, ipAccess, limits and proxyProtocol are valid for protocol %s", spec.Protocol))
+	}
+	if errMsg := ValidateProxyProtocol(spec.ProxyProtocol); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+	if l := spec.Limits; l != nil {
+		if l.ResponseHeaderTimeout != "" || l.Timeout != "" || l.MaxRequestBodyBytes != 0 || l.MaxResponseHeaderBytes != 0 {
+			invalidProps = append(invalidProps, fmt.Sprintf("only limits.connectTimeout and limits.idleTimeout are valid for protocol %s", spec.Protocol))
+		}
+		if errMsg := ValidateLimits(l); errMsg != "" {
+			invalidProps = append(invalidProps, errMsg)
+		}
+	}
+	if errMsg := ValidateIPAccess(spec.IPAccess); errMsg != "" {
+		invalidProps = append(invalidProps, errMsg)
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateHostLabels checks the from of a record and that its targets only
+// use {N} placeholders the host pattern can fill.
+func ValidateHostLabels(from, to string, spec models.ProxySpec) string {
+	pattern, err := ParseHostPattern(from)
+	if err != nil {
+		return err.Error()
+	}
+	labels := 0
+	if pattern != nil {
+		labels = pattern.Labels()
+	}
+
+	var invalidProps []string
+	check := func(field, target string) {
+		if n := maxHostLabel(target); n > labels {
+			invalidProps = append(invalidProps, fmt.Sprintf("%s uses {%d} but %s matches %d labels", field, n, from, labels))
+		}
+	}
+	check("to", to)
+	templated := HasHostLabels(to)
+	for i, u := range spec.Upstreams {
+		check(fmt.Sprintf("upstreams[%d].url", i), u.URL)
+		templated = templated || HasHostLabels(u.URL)
+	}
+	for i, rule := range spec.Routes {
+		check(fmt.Sprintf("routes[%d].to", i), rule.To)
+	}
+	if spec.Mirror != nil {
+		check("mirror.url", spec.Mirror.URL)
+	}
+	if spec.Canary != nil {
+		check("canary.to", spec.Canary.To)
+	}
+	if templated && spec.HealthCheck != nil {
+		invalidProps = append(invalidProps, "healthCheck needs upstreams without host labels")
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// withSampleLabels fills the {N} placeholders of the targets with a sample
+// label so they can be validated as URLs.
+func withSampleLabels(to string, spec models.ProxySpec) (string, models.ProxySpec) {
+	fill := func(s string) string {
+		return hostLabel.ReplaceAllString(s, "label")
+	}
+
+	upstreams := make([]models.Upstream, len(spec.Upstreams))
+	for i, u := range spec.Upstreams {
+		u.URL = fill(u.URL)
+		upstreams[i] = u
+	}
+	routes := make([]models.PathRule, len(spec.Routes))
+	for i, rule := range spec.Routes {
+		rule.To = fill(rule.To)
+		routes[i] = rule
+	}
+	if spec.Upstreams != nil {
+		spec.Upstreams = upstreams
+	}
+	if spec.Routes != nil {
+		spec.Routes = routes
+	}
+	if spec.Mirror != nil {
+		mirror := *spec.Mirror
+		mirror.URL = fill(mirror.URL)
+		spec.Mirror = &mirror
+	}
+	if spec.Canary != nil {
+		canary := *spec.Canary
+		canary.To = fill(canary.To)
+		spec.Canary = &canary
+	}
+	return fill(to), spec
+}
+
+// ValidateCanary checks the canary of a record. Canary responses must not
+// end up in the shared cache, so a canary cannot be combined with caching.
+func ValidateCanary(spec models.ProxySpec) string {
+	c := spec.Canary
+	if c == nil {
+		return ""
+	}
+	var invalidProps []string
+
+	if !isAbsoluteURL(c.To) {
+		invalidProps = append(invalidProps, "canary.to is not a valid url")
+	}
+	if c.Weight < 0 || c.Weight > 100 {
+		invalidProps = append(invalidProps, "canary.weight must be between 0 and 100")
+	}
+	if c.Weight == 0 && len(c.Matches) == 0 {
+		invalidProps = append(invalidProps, "canary needs matches or a weight")
+	}
+	for i, m := range c.Matches {
+		set := 0
+		for _, name := range []string{m.Header, m.Cookie, m.Query} {
+			if name != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			invalidProps = append(invalidProps, fmt.Sprintf("canary.matches[%d] needs exactly one of header, cookie and query", i))
+		}
+	}
+	if spec.Cache != nil {
+		invalidProps = append(invalidProps, "canary and cache cannot be combined")
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateAuth checks the edge authentication of a record.
+func ValidateAuth(auth *models.EdgeAuth) string {
+	if auth == nil {
+		return ""
+	}
+	var invalidProps []string
+
+	switch auth.Type {
+	case models.AuthBasic:
+		if auth.Secret == "" {
+			invalidProps = append(invalidProps, "auth.secret is required for basic auth")
+		}
+	case models.AuthJWT:
+		if !isAbsoluteURL(auth.JWKSURL) {
+			invalidProps = append(invalidProps, "auth.jwksUrl is not a valid url")
+		}
+	case models.AuthForward:
+		if !isAbsoluteURL(auth.URL) {
+			invalidProps = append(invalidProps, "auth.url is not a valid url")
+		}
+	default:
+		invalidProps = append(invalidProps, fmt.Sprintf("unknown auth type %s", auth.Type))
+	}
+	if strings.ContainsAny(auth.Realm, "\"\r\n") {
+		invalidProps = append(invalidProps, "auth.realm must not contain quotes or line breaks")
+	}
+
+	var headers []string
+	for name := range auth.ClaimHeaders {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+	headers = append(headers, auth.AuthHeaders...)
+	for _, name := range headers {
+		if !isHeaderName(name) {
+			invalidProps = append(invalidProps, fmt.Sprintf("auth header %q is not a valid header name", name))
+		}
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateIPAccess checks the allow and deny lists of a record.
+func ValidateIPAccess(access *models.IPAccess) string {
+	if access == nil {
+		return ""
+	}
+	var invalidProps []string
+	if _, err := ParseCIDRs(access.Allow); err != nil {
+		invalidProps = append(invalidProps, "ipAccess.allow: "+err.Error())
+	}
+	if _, err := ParseCIDRs(access.Deny); err != nil {
+		invalidProps = append(invalidProps, "ipAccess.deny: "+err.Error())
+	}
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateCORS checks the CORS policy of a record.
+func ValidateCORS(cors *models.CORS) string {
+	if cors == nil {
+		return ""
+	}
+	var invalidProps []string
+
+	if len(cors.AllowOrigins) == 0 {
+		invalidProps = append(invalidProps, "cors.allowOrigins must not be empty")
+	}
+	for _, o := range cors.AllowOrigins {
+		if expr, ok := strings.CutPrefix(o, RegexHostPrefix); ok {
+			if _, err := regexp.Compile(expr); err != nil || expr == "" {
+				invalidProps = append(invalidProps, fmt.Sprintf("cors origin %s is not a valid regular expression", o))
+			}
+		} else if o == "" || strings.ContainsAny(o, " \t\r\n,") {
+			invalidProps = append(invalidProps, fmt.Sprintf("cors origin %q is not a valid origin", o))
+		}
+	}
+	for _, m := range cors.AllowMethods {
+		if m != "*" && !isHeaderName(m) {
+			invalidProps = append(invalidProps, fmt.Sprintf("cors method %q is not a valid method", m))
+		}
+	}
+	for _, h := range append(slices.Clone(cors.AllowHeaders), cors.ExposeHeaders...) {
+		if h != "*" && !isHeaderName(h) {
+			invalidProps = append(invalidProps, fmt.Sprintf("cors header %q is not a valid header name", h))
+		}
+	}
+	if cors.MaxAge < 0 {
+		invalidProps = append(invalidProps, "cors.maxAge must not be negative")
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateLimits checks the timeouts and size limits of a record. Unlike
+// other durations, "0s" is allowed and turns a limit off.
+func ValidateLimits(l *models.Limits) string {
+	if l == nil {
+		return ""
+	}
+	var invalidProps []string
+	for field, value := range map[string]string{
+		"connectTimeout":        l.ConnectTimeout,
+		"responseHeaderTimeout": l.ResponseHeaderTimeout,
+		"timeout":               l.Timeout,
+		"idleTimeout":           l.IdleTimeout,
+	} {
+		if d, err := time.ParseDuration(value); value != "" && (err != nil || d < 0) {
+			invalidProps = append(invalidProps, fmt.Sprintf("limits.%s %s is not a valid duration", field, value))
+		}
+	}
+	if l.MaxRequestBodyBytes < 0 || l.MaxResponseHeaderBytes < 0 {
+		invalidProps = append(invalidProps, "limits sizes must not be negative")
+	}
+	slices.Sort(invalidProps)
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateUpstreamTLS checks the upstream TLS settings of a record.
+func ValidateUpstreamTLS(t *models.UpstreamTLS) string {
+	if t == nil {
+		return ""
+	}
+	var invalidProps []string
+	if t.ServerName != "" && !isHostname(t.ServerName) {
+		invalidProps = append(invalidProps, fmt.Sprintf("upstreamTLS.serverName %s is not a valid host name", t.ServerName))
+	}
+	if t.InsecureSkipVerify && t.CASecret != "" {
+		invalidProps = append(invalidProps, "upstreamTLS.caSecret has no effect with insecureSkipVerify")
+	}
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateMaintenance checks the maintenance settings of a record.
+func ValidateMaintenance(m *models.Maintenance) string {
+	if m == nil || isDuration(m.RetryAfter) {
+		return ""
+	}
+	return fmt.Sprintf("maintenance.retryAfter %s is not a valid duration", m.RetryAfter)
+}
+
+// ValidateRateLimit checks a per-record or global rate limit.
+func ValidateRateLimit(rl models.RateLimit) string {
+	var invalidProps []string
+
+	if rl.Requests < 1 {
+		invalidProps = append(invalidProps, "rateLimit.requests must be at least 1")
+	}
+	if !isDuration(rl.Period) {
+		invalidProps = append(invalidProps, fmt.Sprintf("rateLimit.period %s is not a valid duration", rl.Period))
+	}
+	if rl.Burst < 0 {
+		invalidProps = append(invalidProps, "rateLimit.burst must not be negative")
+	}
+	switch rl.Key {
+	case "", models.RateLimitKeyIP, models.RateLimitKeyJWTSub:
+	case models.RateLimitKeyHeader:
+		if rl.Header == "" {
+			invalidProps = append(invalidProps, "rateLimit.header is required for the header key")
+		}
+	default:
+		invalidProps = append(invalidProps, fmt.Sprintf("unknown rate limit key %s", rl.Key))
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+// ValidateHeaderRules checks that header names are valid tokens and values do
+// not contain line breaks.
+func ValidateHeaderRules(field string, rules *models.HeaderRules) string {
+	if rules == nil {
+		return ""
+	}
+	var invalidProps []string
+
+	checkName := func(name string) {
+		if !isHeaderName(name) {
+			invalidProps = append(invalidProps, fmt.Sprintf("%s: invalid header name %q", field, name))
+		}
+	}
+	checkValue := func(name, value string) {
+		if strings.ContainsAny(value, "\r\n") {
+			invalidProps = append(invalidProps, fmt.Sprintf("%s: value of %s contains a line break", field, name))
+		}
+	}
+	for name, value := range rules.Set {
+		checkName(name)
+		checkValue(name, value)
+	}
+	for name, value := range rules.Add {
+		checkName(name)
+		checkValue(name, value)
+	}
+	for _, name := range rules.Remove {
+		checkName(name)
+	}
+
+	sort.Strings(invalidProps)
+	return strings.Join(invalidProps, ", ")
+}
+
+func isHeaderName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\r\n:()<>@,;\\\"/[]?={}")
+}
+
+// isDuration reports whether value is empty or a positive Go duration.
+func isDuration(value string) bool {
+	if value == "" {
+		return true
+	}
+	d, err := time.ParseDuration(value)
+	return err == nil && d > 0
+}
+
+// ValidatePathRules checks that every path rule has an absolute prefix, a
+// usable target URL and that no prefix is declared twice.
+func ValidatePathRules(rules []models.PathRule) string {
+	var invalidProps []string
+	seen := make(map[string]bool)
+
+	for i, rule := range rules {
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			invalidProps = append(invalidProps, fmt.Sprintf("routes[%d].prefix must start with /", i))
+		}
+		if seen[rule.Prefix] {
+			invalidProps = append(invalidProps, fmt.Sprintf("routes[%d].prefix %s is duplicated", i, rule.Prefix))
+		}
+		seen[rule.Prefix] = true
+
+		if !isAbsoluteURL(rule.To) {
+			invalidProps = append(invalidProps, fmt.Sprintf("routes[%d].to is not a valid url", i))
+		}
+	}
+
+	return strings.Join(invalidProps, ", ")
+}
+
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// isHostPort reports whether target is a host:port without a scheme, as
+// dialled by layer 4 records.
+func isHostPort(target string) bool {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil || host == "" {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func isHostname(name string) bool {
+	return len(name) <= 253 && hostnamePattern.MatchString(name)
+}
+
+// ValidateProxyProtocol checks the PROXY protocol version a record sends to
+// its upstreams.
+func ValidateProxyProtocol(version string) string {
+	switch version {
+	case "", models.ProxyProtocolV1, models.ProxyProtocolV2:
+		return ""
+	}
+	return fmt.Sprintf("unknown proxyProtocol %s, use v1 or v2", version)
+}